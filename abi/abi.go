@@ -17,22 +17,165 @@ import (
 // ABI represents the ethereum abi format
 type ABI struct {
 	Constructor *Method
-	Methods     map[string]*Method
-	Events      map[string]*Event
+
+	// Methods are the methods of the contract by name. If the method
+	// is overloaded, it is the first one declared in the abi.
+	Methods map[string]*Method
+
+	// MethodsBySignature are all the methods of the contract,
+	// including the overloads, by signature
+	MethodsBySignature map[string]*Method
+
+	Events map[string]*Event
+	Errors map[string]*Error
+
+	// Fallback and Receive are the special functions of the
+	// contract, they are nil if the contract does not have them
+	Fallback *Method
+	Receive  *Method
+
+	// overloads are the methods with the same name in order of declaration
+	overloads map[string][]*Method
 }
 
 func (a *ABI) addEvent(e *Event) {
-	if len(a.Methods) == 0 {
+	if a.Events == nil {
 		a.Events = map[string]*Event{}
 	}
 	a.Events[e.Name] = e
 }
 
 func (a *ABI) addMethod(m *Method) {
-	if len(a.Methods) == 0 {
+	if a.Methods == nil {
 		a.Methods = map[string]*Method{}
 	}
-	a.Methods[m.Name] = m
+	if a.MethodsBySignature == nil {
+		a.MethodsBySignature = map[string]*Method{}
+	}
+	if a.overloads == nil {
+		a.overloads = map[string][]*Method{}
+	}
+	if _, ok := a.Methods[m.Name]; !ok {
+		a.Methods[m.Name] = m
+	}
+	a.MethodsBySignature[m.Sig()] = m
+	a.overloads[m.Name] = append(a.overloads[m.Name], m)
+}
+
+// GetMethod returns the method by name or by signature
+// (i.e. 'transfer(address,uint256)'). It returns nil if not found.
+func (a *ABI) GetMethod(name string) *Method {
+	if !strings.Contains(name, "(") {
+		return a.Methods[name]
+	}
+	return a.GetMethodBySignature(name)
+}
+
+// GetMethodBySignature returns the method with the given signature.
+// It returns nil if not found.
+func (a *ABI) GetMethodBySignature(sig string) *Method {
+	if m, ok := a.MethodsBySignature[sig]; ok {
+		return m
+	}
+	// normalize the signature (i.e. remove the names of the arguments)
+	m, err := NewMethod(sig)
+	if err != nil {
+		return nil
+	}
+	return a.MethodsBySignature[m.Sig()]
+}
+
+// DecodeInput finds the method of the input of a transaction
+// by its selector and decodes the arguments
+func (a *ABI) DecodeInput(data []byte) (*Method, map[string]interface{}, error) {
+	if len(data) < 4 {
+		return nil, nil, fmt.Errorf("input too short")
+	}
+
+	methods := a.MethodsBySignature
+	if methods == nil {
+		methods = a.Methods
+	}
+	for _, m := range methods {
+		if !bytes.Equal(data[:4], m.ID()) {
+			continue
+		}
+		args, err := decodeArgs(m.Inputs, data[4:])
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode input of method %s: %v", m.Name, err)
+		}
+		return m, args, nil
+	}
+	return nil, nil, fmt.Errorf("method with selector 0x%x not found", data[:4])
+}
+
+// Overloads returns all the methods with the given name in order of declaration
+func (a *ABI) Overloads(name string) []*Method {
+	if methods, ok := a.overloads[name]; ok {
+		return methods
+	}
+	if m, ok := a.Methods[name]; ok {
+		return []*Method{m}
+	}
+	return nil
+}
+
+// FindMethod returns the method to call with the arguments. The method is
+// either a signature or a name, in which case the overload is selected by
+// the number of arguments and whether they can be encoded with its types.
+func (a *ABI) FindMethod(name string, args ...interface{}) (*Method, error) {
+	if strings.Contains(name, "(") {
+		m := a.GetMethodBySignature(name)
+		if m == nil {
+			return nil, fmt.Errorf("method %s not found", name)
+		}
+		return m, nil
+	}
+
+	methods := a.Overloads(name)
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("method %s not found", name)
+	}
+	if len(methods) == 1 {
+		return methods[0], nil
+	}
+
+	candidates := []*Method{}
+	for _, m := range methods {
+		if len(m.Inputs.TupleElems()) == len(args) {
+			candidates = append(candidates, m)
+		}
+	}
+	if len(candidates) > 1 {
+		// select the overloads whose types match the arguments
+		matches := []*Method{}
+		for _, m := range candidates {
+			if _, err := Encode(args, m.Inputs); err == nil {
+				matches = append(matches, m)
+			}
+		}
+		candidates = matches
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil, fmt.Errorf("no overload of method %s matches the arguments", name)
+	case 1:
+		return candidates[0], nil
+	default:
+		sigs := []string{}
+		for _, m := range candidates {
+			sigs = append(sigs, m.Sig())
+		}
+		return nil, fmt.Errorf("ambiguous overloads of method %s: %s", name, strings.Join(sigs, ", "))
+	}
+}
+
+func (a *ABI) addError(e *Error) {
+	if a.Errors == nil {
+		a.Errors = map[string]*Error{}
+	}
+	a.Errors[e.Name] = e
 }
 
 // NewABI returns a parsed ABI struct
@@ -65,6 +208,7 @@ func (a *ABI) UnmarshalJSON(data []byte) error {
 		Type            string
 		Name            string
 		Constant        bool
+		Payable         bool
 		Anonymous       bool
 		StateMutability string
 		Inputs          arguments
@@ -76,16 +220,35 @@ func (a *ABI) UnmarshalJSON(data []byte) error {
 	}
 
 	a.Methods = make(map[string]*Method)
+	a.MethodsBySignature = make(map[string]*Method)
+	a.overloads = make(map[string][]*Method)
 	a.Events = make(map[string]*Event)
+	a.Errors = make(map[string]*Error)
 
 	for _, field := range fields {
+		payable := field.Payable || field.StateMutability == "payable"
+
+		mutability := field.StateMutability
+		if mutability == "" {
+			// legacy abi without the state mutability
+			if payable {
+				mutability = "payable"
+			} else if field.Constant {
+				mutability = "view"
+			} else {
+				mutability = "nonpayable"
+			}
+		}
+
 		switch field.Type {
 		case "constructor":
 			if a.Constructor != nil {
 				return fmt.Errorf("multiple constructor declaration")
 			}
 			a.Constructor = &Method{
-				Inputs: field.Inputs.Type(),
+				Payable:         payable,
+				StateMutability: mutability,
+				Inputs:          field.Inputs.Type(),
 			}
 
 		case "function", "":
@@ -94,12 +257,14 @@ func (a *ABI) UnmarshalJSON(data []byte) error {
 				c = true
 			}
 
-			a.Methods[field.Name] = &Method{
-				Name:    field.Name,
-				Const:   c,
-				Inputs:  field.Inputs.Type(),
-				Outputs: field.Outputs.Type(),
-			}
+			a.addMethod(&Method{
+				Name:            field.Name,
+				Const:           c,
+				Payable:         payable,
+				StateMutability: mutability,
+				Inputs:          field.Inputs.Type(),
+				Outputs:         field.Outputs.Type(),
+			})
 
 		case "event":
 			a.Events[field.Name] = &Event{
@@ -108,9 +273,35 @@ func (a *ABI) UnmarshalJSON(data []byte) error {
 				Inputs:    field.Inputs.Type(),
 			}
 
+		case "error":
+			a.Errors[field.Name] = &Error{
+				Name:   field.Name,
+				Inputs: field.Inputs.Type(),
+			}
+
 		case "fallback":
+			if a.Fallback != nil {
+				return fmt.Errorf("multiple fallback declaration")
+			}
+			a.Fallback = &Method{
+				Name:            "fallback",
+				Payable:         payable,
+				StateMutability: mutability,
+				Inputs:          field.Inputs.Type(),
+				Outputs:         field.Outputs.Type(),
+			}
+
 		case "receive":
-			// do nothing
+			if a.Receive != nil {
+				return fmt.Errorf("multiple receive declaration")
+			}
+			a.Receive = &Method{
+				Name:            "receive",
+				Payable:         true,
+				StateMutability: "payable",
+				Inputs:          field.Inputs.Type(),
+				Outputs:         field.Outputs.Type(),
+			}
 
 		default:
 			return fmt.Errorf("unknown field type '%s'", field.Type)
@@ -123,6 +314,11 @@ func (a *ABI) UnmarshalJSON(data []byte) error {
 type Method struct {
 	Name    string
 	Const   bool
+	Payable bool
+
+	// StateMutability is either pure, view, nonpayable or payable
+	StateMutability string
+
 	Inputs  *Type
 	Outputs *Type
 }
@@ -141,18 +337,72 @@ func (m *Method) ID() []byte {
 	return dst
 }
 
-func NewMethod(name string) (*Method, error) {
-	name, inputs, outputs, err := parseMethodSignature(name)
+// DecodeInput decodes the arguments of the method from the
+// input of a transaction, including the method selector
+func (m *Method) DecodeInput(data []byte) (map[string]interface{}, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("input too short")
+	}
+	if !bytes.Equal(data[:4], m.ID()) {
+		return nil, fmt.Errorf("input does not match the method %s", m.Name)
+	}
+	return decodeArgs(m.Inputs, data[4:])
+}
+
+// DecodeOutput decodes the return data of the method
+func (m *Method) DecodeOutput(data []byte) (map[string]interface{}, error) {
+	return decodeArgs(m.Outputs, data)
+}
+
+// MustNewMethod creates a new method from its signature or fails
+func MustNewMethod(sig string) *Method {
+	m, err := NewMethod(sig)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// NewMethod creates a new method from its human readable signature
+// (i.e. 'function balanceOf(address owner) view returns (uint256)')
+func NewMethod(sig string) (*Method, error) {
+	name, inputs, outputs, err := parseMethodSignature(sig)
 	if err != nil {
 		return nil, err
 	}
 	m := &Method{Name: name, Inputs: inputs, Outputs: outputs}
+
+	m.StateMutability = parseStateMutability(sig)
+	m.Const = m.StateMutability == "view" || m.StateMutability == "pure"
+	m.Payable = m.StateMutability == "payable"
 	return m, nil
 }
 
+// parseStateMutability returns the state mutability from the
+// modifiers after the inputs of a method signature
+func parseStateMutability(sig string) string {
+	var matches [][]string
+	if strings.Contains(sig, "returns") {
+		matches = funcRegexpWithReturn.FindAllStringSubmatch(sig, -1)
+	} else {
+		matches = funcRegexpWithoutReturn.FindAllStringSubmatch(sig, -1)
+	}
+	if len(matches) != 0 {
+		for _, modifier := range strings.Fields(matches[0][3]) {
+			switch modifier {
+			case "pure", "view", "payable":
+				return modifier
+			case "constant":
+				return "view"
+			}
+		}
+	}
+	return "nonpayable"
+}
+
 var (
-	funcRegexpWithReturn    = regexp.MustCompile(`(.*)\((.*)\)(.*) returns \((.*)\)`)
-	funcRegexpWithoutReturn = regexp.MustCompile(`(.*)\((.*)\)(.*)`)
+	funcRegexpWithReturn    = regexp.MustCompile(`([^(]*)\((.*)\)(.*) returns \((.*)\)`)
+	funcRegexpWithoutReturn = regexp.MustCompile(`([^(]*)\((.*)\)(.*)`)
 )
 
 func parseMethodSignature(name string) (string, *Type, *Type, error) {
@@ -222,11 +472,16 @@ func MustNewEvent(name string) *Event {
 
 // NewEvent creates a new solidity event object using the signature
 func NewEvent(name string) (*Event, error) {
+	anonymous := strings.HasSuffix(name, " anonymous")
+	name = strings.TrimSuffix(name, " anonymous")
+
 	name, typ, err := parseEventSignature(name)
 	if err != nil {
 		return nil, err
 	}
-	return NewEventFromType(name, typ), nil
+	evnt := NewEventFromType(name, typ)
+	evnt.Anonymous = anonymous
+	return evnt, nil
 }
 
 func parseEventSignature(name string) (string, *Type, error) {
@@ -273,6 +528,85 @@ func (e *Event) ParseLog(log *web3.Log) (map[string]interface{}, error) {
 	return e.Inputs.ParseLog(log)
 }
 
+// ParseLogStruct parses a log of this event into a struct
+func (e *Event) ParseLogStruct(log *web3.Log, out interface{}) error {
+	if !e.Match(log) {
+		return fmt.Errorf("log does not match this event")
+	}
+	return ParseLogStruct(e.Inputs, log, out)
+}
+
+// Error is a custom solidity error
+type Error struct {
+	Name   string
+	Inputs *Type
+}
+
+// Sig returns the signature of the error
+func (e *Error) Sig() string {
+	return buildSignature(e.Name, e.Inputs)
+}
+
+// ID returns the selector of the error in the revert data
+func (e *Error) ID() []byte {
+	k := acquireKeccak()
+	k.Write([]byte(e.Sig()))
+	dst := k.Sum(nil)[:4]
+	releaseKeccak(k)
+	return dst
+}
+
+// MustNewError creates a new solidity error object or fails
+func MustNewError(name string) *Error {
+	e, err := NewError(name)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// NewError creates a new solidity error object using the signature
+func NewError(name string) (*Error, error) {
+	name, typ, err := parseEventSignature(strings.TrimPrefix(name, "error "))
+	if err != nil {
+		return nil, err
+	}
+	return NewErrorFromType(name, typ), nil
+}
+
+// NewErrorFromType creates a new solidity error object using the name and type
+func NewErrorFromType(name string, typ *Type) *Error {
+	return &Error{Name: name, Inputs: typ}
+}
+
+// Match checks whether the revert data is from this error
+func (e *Error) Match(data []byte) bool {
+	if len(data) < 4 {
+		return false
+	}
+	return bytes.Equal(data[:4], e.ID())
+}
+
+// Decode decodes the arguments of the error from the revert data
+func (e *Error) Decode(data []byte) (map[string]interface{}, error) {
+	if !e.Match(data) {
+		return nil, fmt.Errorf("data does not match this error")
+	}
+	return decodeArgs(e.Inputs, data[4:])
+}
+
+// decodeArgs decodes the data of a tuple of arguments
+func decodeArgs(t *Type, data []byte) (map[string]interface{}, error) {
+	if len(t.TupleElems()) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	val, err := Decode(t, data)
+	if err != nil {
+		return nil, err
+	}
+	return val.(map[string]interface{}), nil
+}
+
 func buildSignature(name string, typ *Type) string {
 	types := make([]string, len(typ.tuple))
 	for i, input := range typ.tuple {
@@ -282,9 +616,10 @@ func buildSignature(name string, typ *Type) string {
 }
 
 type argument struct {
-	Name    string
-	Type    *Type
-	Indexed bool
+	Name         string
+	Type         *Type
+	Indexed      bool
+	InternalType string
 }
 
 type arguments []*argument
@@ -293,9 +628,10 @@ func (a *arguments) Type() *Type {
 	inputs := []*TupleElem{}
 	for _, i := range *a {
 		inputs = append(inputs, &TupleElem{
-			Name:    i.Name,
-			Elem:    i.Type,
-			Indexed: i.Indexed,
+			Name:         i.Name,
+			Elem:         i.Type,
+			Indexed:      i.Indexed,
+			InternalType: i.InternalType,
 		})
 	}
 
@@ -321,15 +657,17 @@ func (a *argument) UnmarshalJSON(data []byte) error {
 	a.Type = t
 	a.Name = arg.Name
 	a.Indexed = arg.Indexed
+	a.InternalType = arg.InternalType
 	return nil
 }
 
 // ArgumentStr encodes a type object
 type ArgumentStr struct {
-	Name       string
-	Type       string
-	Indexed    bool
-	Components []*ArgumentStr
+	Name         string
+	Type         string
+	InternalType string
+	Indexed      bool
+	Components   []*ArgumentStr
 }
 
 var keccakPool = sync.Pool{
@@ -362,8 +700,34 @@ func NewABIFromList(humanReadableAbi []string) (*ABI, error) {
 				return nil, err
 			}
 			res.addEvent(evnt)
+		} else if strings.HasPrefix(c, "error ") {
+			e, err := NewError(c)
+			if err != nil {
+				return nil, err
+			}
+			res.addError(e)
+		} else if strings.HasPrefix(c, "constructor(") {
+			method, err := NewMethod(c)
+			if err != nil {
+				return nil, err
+			}
+			method.Name = ""
+			method.Outputs = nil
+			res.Constructor = method
+		} else if strings.HasPrefix(c, "fallback(") {
+			method, err := NewMethod(c)
+			if err != nil {
+				return nil, err
+			}
+			res.Fallback = method
+		} else if strings.HasPrefix(c, "receive(") {
+			method, err := NewMethod(c)
+			if err != nil {
+				return nil, err
+			}
+			res.Receive = method
 		} else {
-			return nil, fmt.Errorf("either event or function expected")
+			return nil, fmt.Errorf("either event, function or error expected")
 		}
 	}
 	return res, nil
@@ -2,9 +2,11 @@ package abi
 
 import (
 	"fmt"
+	"math/big"
 	"reflect"
 	"testing"
 
+	web3 "github.com/mover-code/golang-web3"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -20,16 +22,25 @@ func TestAbi(t *testing.T) {
 					"type": "function"
 				}
 			]`,
-			Output: &ABI{
-				Methods: map[string]*Method{
-					"abc": &Method{
-						Name:    "abc",
-						Inputs:  &Type{kind: KindTuple, raw: "tuple", tuple: []*TupleElem{}},
-						Outputs: &Type{kind: KindTuple, raw: "tuple", tuple: []*TupleElem{}},
+			Output: func() *ABI {
+				m := &Method{
+					Name:            "abc",
+					StateMutability: "nonpayable",
+					Inputs:          &Type{kind: KindTuple, raw: "tuple", tuple: []*TupleElem{}},
+					Outputs:         &Type{kind: KindTuple, raw: "tuple", tuple: []*TupleElem{}},
+				}
+				return &ABI{
+					Methods: map[string]*Method{
+						"abc": m,
+					},
+					MethodsBySignature: map[string]*Method{
+						"abc()": m,
 					},
-				},
-				Events: map[string]*Event{},
-			},
+					Events:    map[string]*Event{},
+					Errors:    map[string]*Error{},
+					overloads: map[string][]*Method{"abc": {m}},
+				}
+			}(),
 		},
 	}
 
@@ -108,3 +119,128 @@ func TestAbi_ParseMethodSignature(t *testing.T) {
 		}
 	}
 }
+
+func TestAbi_FallbackReceive(t *testing.T) {
+	abi, err := NewABI(`[
+		{"type": "fallback", "stateMutability": "payable"},
+		{"type": "receive", "stateMutability": "payable"},
+		{"type": "function", "name": "deposit", "stateMutability": "payable"}
+	]`)
+	assert.NoError(t, err)
+
+	assert.NotNil(t, abi.Fallback)
+	assert.True(t, abi.Fallback.Payable)
+	assert.NotNil(t, abi.Receive)
+	assert.True(t, abi.Receive.Payable)
+	assert.True(t, abi.Methods["deposit"].Payable)
+	assert.False(t, abi.Methods["deposit"].Const)
+}
+
+func TestAbi_Errors(t *testing.T) {
+	abi, err := NewABIFromList([]string{
+		"error Unauthorized(address account)",
+		"error Empty()",
+	})
+	assert.NoError(t, err)
+
+	e := abi.Errors["Unauthorized"]
+	assert.Equal(t, e.Sig(), "Unauthorized(address)")
+	assert.Equal(t, e.ID(), []byte{0x8e, 0x4a, 0x23, 0xd6})
+
+	data := append(e.ID(), make([]byte, 32)...)
+	data[35] = 0x1
+
+	assert.True(t, e.Match(data))
+	assert.False(t, abi.Errors["Empty"].Match(data))
+
+	args, err := e.Decode(data)
+	assert.NoError(t, err)
+	assert.Equal(t, args["account"], web3.Address{0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x1})
+
+	args, err = abi.Errors["Empty"].Decode(abi.Errors["Empty"].ID())
+	assert.NoError(t, err)
+	assert.Len(t, args, 0)
+
+	_, err = abi.Errors["Empty"].Decode(data)
+	assert.Error(t, err)
+}
+
+func TestAbi_Overloads(t *testing.T) {
+	abi, err := NewABIFromList([]string{
+		"function safeTransferFrom(address from, address to, uint256 id)",
+		"function safeTransferFrom(address from, address to, uint256 id, bytes data)",
+		"function set(uint256 val)",
+		"function set(string val)",
+	})
+	assert.NoError(t, err)
+
+	assert.Len(t, abi.Overloads("safeTransferFrom"), 2)
+	assert.Len(t, abi.MethodsBySignature, 4)
+
+	// the first declaration is the one by name
+	assert.Equal(t, abi.Methods["safeTransferFrom"].Sig(), "safeTransferFrom(address,address,uint256)")
+
+	// by signature
+	m := abi.GetMethod("safeTransferFrom(address,address,uint256,bytes)")
+	assert.Equal(t, m.Sig(), "safeTransferFrom(address,address,uint256,bytes)")
+
+	m = abi.GetMethodBySignature("safeTransferFrom(address a, address b, uint256 id, bytes)")
+	assert.Equal(t, m.Sig(), "safeTransferFrom(address,address,uint256,bytes)")
+
+	// by number of arguments
+	addr := web3.Address{0x1}
+	m, err = abi.FindMethod("safeTransferFrom", addr, addr, big.NewInt(1), []byte{0x1})
+	assert.NoError(t, err)
+	assert.Equal(t, m.Sig(), "safeTransferFrom(address,address,uint256,bytes)")
+
+	// by type of arguments
+	m, err = abi.FindMethod("set", "a")
+	assert.NoError(t, err)
+	assert.Equal(t, m.Sig(), "set(string)")
+
+	m, err = abi.FindMethod("set", big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, m.Sig(), "set(uint256)")
+
+	_, err = abi.FindMethod("set", true)
+	assert.Error(t, err)
+
+	_, err = abi.FindMethod("other")
+	assert.Error(t, err)
+}
+
+func TestAbi_DecodeInput(t *testing.T) {
+	abi, err := NewABIFromList([]string{
+		"function transfer(address to, uint256 amount) returns (bool)",
+		"function pause()",
+	})
+	assert.NoError(t, err)
+
+	transfer := abi.Methods["transfer"]
+
+	to := web3.Address{0x1}
+	data, err := Encode([]interface{}{to, big.NewInt(10)}, transfer.Inputs)
+	assert.NoError(t, err)
+	data = append(transfer.ID(), data...)
+
+	m, args, err := abi.DecodeInput(data)
+	assert.NoError(t, err)
+	assert.Equal(t, m, transfer)
+	assert.Equal(t, args["to"], to)
+	assert.Equal(t, args["amount"], big.NewInt(10))
+
+	// method without arguments
+	m, args, err = abi.DecodeInput(abi.Methods["pause"].ID())
+	assert.NoError(t, err)
+	assert.Equal(t, m.Name, "pause")
+	assert.Len(t, args, 0)
+
+	// unknown selector
+	_, _, err = abi.DecodeInput([]byte{0x1, 0x2, 0x3, 0x4})
+	assert.Error(t, err)
+
+	// return data
+	out, err := transfer.DecodeOutput(append(make([]byte, 31), 0x1))
+	assert.NoError(t, err)
+	assert.Equal(t, out["0"], true)
+}
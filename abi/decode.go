@@ -8,7 +8,7 @@ import (
 	"strconv"
 
 	"github.com/mover-code/golang-web3"
-	"github.com/mitchellh/mapstructure"
+	"github.com/shopspring/decimal"
 )
 
 // Decode decodes the input with a given type
@@ -20,16 +20,32 @@ func Decode(t *Type, input []byte) (interface{}, error) {
 	return val, err
 }
 
-// DecodeStruct decodes the input with a type to a struct
+// DecodeStruct decodes the input with a type to a struct. The elements
+// of the tuples are matched with the 'abi' tag of the fields, the
+// 'mapstructure' tag if there is no 'abi' tag or with the name of the
+// fields without case, the elements without field are ignored.
+// Nested tuples are decoded into structs and arrays of tuples into slices
+// or arrays of structs.
 func DecodeStruct(t *Type, input []byte, out interface{}) error {
-	val, err := Decode(t, input)
-	if err != nil {
-		return err
+	return decodeStruct(t, input, out, false)
+}
+
+// DecodeStructStrict decodes the input with a type to a struct like
+// DecodeStruct but it fails if any element of the tuples has no field
+func DecodeStructStrict(t *Type, input []byte, out interface{}) error {
+	return decodeStruct(t, input, out, true)
+}
+
+func decodeStruct(t *Type, input []byte, out interface{}, strict bool) error {
+	if len(input) == 0 {
+		return fmt.Errorf("empty input")
 	}
-	if err := mapstructure.Decode(val, out); err != nil {
-		return err
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("expected a pointer but found %T", out)
 	}
-	return nil
+	_, err := decodeInto(t, input, v.Elem(), strict)
+	return err
 }
 
 func decode(t *Type, input []byte) (interface{}, []byte, error) {
@@ -85,6 +101,9 @@ func decode(t *Type, input []byte) (interface{}, []byte, error) {
 	case KindFunction:
 		val, err = readFunctionType(t, data)
 
+	case KindFixedPoint:
+		val = readFixedPoint(t, data)
+
 	default:
 		return nil, nil, fmt.Errorf("decoding not available for type '%s'", t.kind)
 	}
@@ -151,15 +170,24 @@ func readInteger(t *Type, b []byte) interface{} {
 	}
 }
 
-func readFunctionType(t *Type, word []byte) ([24]byte, error) {
-	res := [24]byte{}
+func readFunctionType(t *Type, word []byte) (FunctionRef, error) {
+	res := FunctionRef{}
 	if !allZeros(word[24:32]) {
 		return res, fmt.Errorf("function type expects the last 8 bytes to be empty but found: %b", word[24:32])
 	}
-	copy(res[:], word[0:24])
+	copy(res.Address[:], word[0:20])
+	copy(res.Selector[:], word[20:24])
 	return res, nil
 }
 
+func readFixedPoint(t *Type, word []byte) decimal.Decimal {
+	ret := new(big.Int).SetBytes(word)
+	if t.signed && ret.Cmp(maxInt256) > 0 {
+		ret.Sub(ret, new(big.Int).Add(maxUint256, big.NewInt(1)))
+	}
+	return decimal.NewFromBigInt(ret, -int32(t.decimals))
+}
+
 func readFixedBytes(t *Type, word []byte) (interface{}, error) {
 	array := reflect.New(t.t).Elem()
 	reflect.Copy(array, reflect.ValueOf(word[0:t.size]))
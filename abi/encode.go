@@ -5,7 +5,8 @@ import (
 	"math/big"
 	"reflect"
 	"strconv"
-	"strings"
+
+	"github.com/shopspring/decimal"
 )
 
 var (
@@ -45,9 +46,15 @@ func encode(v reflect.Value, t *Type) ([]byte, error) {
 	case KindBytes:
 		return encodeBytes(v)
 
-	case KindFixedBytes, KindFunction:
+	case KindFixedBytes:
 		return encodeFixedBytes(v)
 
+	case KindFunction:
+		return encodeFunction(v)
+
+	case KindFixedPoint:
+		return encodeFixedPoint(v, t)
+
 	default:
 		return nil, fmt.Errorf("encoding not available for type '%s'", t.kind)
 	}
@@ -96,11 +103,41 @@ func encodeSliceAndArray(v reflect.Value, t *Type) ([]byte, error) {
 }
 
 func encodeTuple(v reflect.Value, t *Type) ([]byte, error) {
+	values, err := tupleValues(v, t)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := 0
+	for _, elem := range t.tuple {
+		offset += getTypeSize(elem.Elem)
+	}
+
+	var ret, tail []byte
+	for i, elem := range t.tuple {
+		val, err := encode(values[i], elem.Elem)
+		if err != nil {
+			return nil, err
+		}
+		if elem.Elem.isDynamicType() {
+			ret = append(ret, packNum(offset)...)
+			tail = append(tail, val...)
+			offset += len(val)
+		} else {
+			ret = append(ret, val...)
+		}
+	}
+
+	return append(ret, tail...), nil
+}
+
+// tupleValues returns the values for each element of the tuple
+// from either a list, a map or a struct
+func tupleValues(v reflect.Value, t *Type) ([]reflect.Value, error) {
 	if v.Kind() == reflect.Ptr {
 		v = v.Elem()
 	}
 
-	var err error
 	isList := true
 
 	switch v.Kind() {
@@ -109,11 +146,15 @@ func encodeTuple(v reflect.Value, t *Type) ([]byte, error) {
 		isList = false
 
 	case reflect.Struct:
-		isList = false
-		v, err = mapFromStruct(v)
-		if err != nil {
-			return nil, err
+		values := make([]reflect.Value, len(t.tuple))
+		for i, elem := range t.tuple {
+			field, err := structField(v, elem, i)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = field
 		}
+		return values, nil
 
 	default:
 		return nil, encodeErr(v, "tuple")
@@ -123,13 +164,8 @@ func encodeTuple(v reflect.Value, t *Type) ([]byte, error) {
 		return nil, fmt.Errorf("expected at least the same length")
 	}
 
-	offset := 0
-	for _, elem := range t.tuple {
-		offset += getTypeSize(elem.Elem)
-	}
-
-	var ret, tail []byte
 	var aux reflect.Value
+	values := make([]reflect.Value, len(t.tuple))
 
 	for i, elem := range t.tuple {
 		if isList {
@@ -144,21 +180,9 @@ func encodeTuple(v reflect.Value, t *Type) ([]byte, error) {
 		if aux.Kind() == reflect.Invalid {
 			return nil, fmt.Errorf("cannot get key %s", elem.Name)
 		}
-
-		val, err := encode(aux, elem.Elem)
-		if err != nil {
-			return nil, err
-		}
-		if elem.Elem.isDynamicType() {
-			ret = append(ret, packNum(offset)...)
-			tail = append(tail, val...)
-			offset += len(val)
-		} else {
-			ret = append(ret, val...)
-		}
+		values[i] = aux
 	}
-
-	return append(ret, tail...), nil
+	return values, nil
 }
 
 func convertArrayToBytes(value reflect.Value) reflect.Value {
@@ -174,10 +198,86 @@ func encodeFixedBytes(v reflect.Value) ([]byte, error) {
 	return rightPad(v.Bytes(), 32), nil
 }
 
+func encodeFunction(v reflect.Value) ([]byte, error) {
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if f, ok := v.Interface().(FunctionRef); ok {
+		return rightPad(f.Bytes(), 32), nil
+	}
+	if v.Kind() != reflect.Array && v.Kind() != reflect.Slice {
+		return nil, encodeErr(v, "function")
+	}
+	return encodeFixedBytes(v)
+}
+
+// fixedPointValue returns the integer value of a fixed point number
+// scaled by its decimals. It fails if the number cannot be represented
+// exactly with the type.
+func fixedPointValue(v reflect.Value, t *Type) (*big.Int, error) {
+	if v.Kind() == reflect.Ptr && v.Type() != bigIntT {
+		v = v.Elem()
+	}
+
+	var d decimal.Decimal
+	switch obj := v.Interface().(type) {
+	case decimal.Decimal:
+		d = obj
+	case *big.Int:
+		d = decimal.NewFromBigInt(obj, 0)
+	case string:
+		var err error
+		if d, err = decimal.NewFromString(obj); err != nil {
+			return nil, err
+		}
+	case float32:
+		d = decimal.NewFromFloat32(obj)
+	case float64:
+		d = decimal.NewFromFloat(obj)
+	default:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			d = decimal.NewFromInt(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			d = decimal.NewFromBigInt(new(big.Int).SetUint64(v.Uint()), 0)
+		default:
+			return nil, encodeErr(v, "fixed point")
+		}
+	}
+
+	scaled := d.Shift(int32(t.decimals))
+	if !scaled.IsInteger() {
+		return nil, fmt.Errorf("%s has more than %d decimals", d, t.decimals)
+	}
+	num := scaled.BigInt()
+
+	// check the number fits in the size of the type
+	if t.signed {
+		max := new(big.Int).Lsh(big.NewInt(1), uint(t.size-1))
+		if num.Cmp(new(big.Int).Neg(max)) < 0 || num.Cmp(max) >= 0 {
+			return nil, fmt.Errorf("%s overflows %s", d, t.raw)
+		}
+	} else if num.Sign() < 0 || num.BitLen() > t.size {
+		return nil, fmt.Errorf("%s overflows %s", d, t.raw)
+	}
+	return num, nil
+}
+
+func encodeFixedPoint(v reflect.Value, t *Type) ([]byte, error) {
+	num, err := fixedPointValue(v, t)
+	if err != nil {
+		return nil, err
+	}
+	return toU256(num), nil
+}
+
 func encodeAddress(v reflect.Value) ([]byte, error) {
 	if v.Kind() == reflect.Array {
 		v = convertArrayToBytes(v)
 	}
+	if !isByteSlice(v) {
+		return nil, encodeErr(v, "address")
+	}
 	return leftPad(v.Bytes(), 32), nil
 }
 
@@ -185,9 +285,16 @@ func encodeBytes(v reflect.Value) ([]byte, error) {
 	if v.Kind() == reflect.Array {
 		v = convertArrayToBytes(v)
 	}
+	if !isByteSlice(v) {
+		return nil, encodeErr(v, "bytes")
+	}
 	return packBytesSlice(v.Bytes(), v.Len())
 }
 
+func isByteSlice(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8
+}
+
 func encodeString(v reflect.Value) ([]byte, error) {
 	if v.Kind() != reflect.String {
 		return nil, encodeErr(v, "string")
@@ -241,33 +348,6 @@ func encodeErr(v reflect.Value, t string) error {
 	return fmt.Errorf("failed to encode %s as %s", v.Kind().String(), t)
 }
 
-func mapFromStruct(v reflect.Value) (reflect.Value, error) {
-	res := map[string]interface{}{}
-	typ := v.Type()
-	for i := 0; i < v.NumField(); i++ {
-		f := typ.Field(i)
-		if f.PkgPath != "" {
-			continue
-		}
-
-		tagValue := f.Tag.Get("abi")
-		if tagValue == "-" {
-			continue
-		}
-
-		name := f.Name
-		if tagValue != "" {
-			name = tagValue
-		}
-
-		name = strings.ToLower(name)
-		if _, ok := res[name]; !ok {
-			res[name] = v.Field(i).Interface()
-		}
-	}
-	return reflect.ValueOf(res), nil
-}
-
 var (
 	tt256   = new(big.Int).Lsh(big.NewInt(1), 256)   // 2 ** 256
 	tt256m1 = new(big.Int).Sub(tt256, big.NewInt(1)) // 2 ** 256 - 1
@@ -15,6 +15,8 @@ import (
 	"github.com/mover-code/golang-web3"
 	"github.com/mover-code/golang-web3/compiler"
 	"github.com/mover-code/golang-web3/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
 )
 
 func encodeHex(b []byte) string {
@@ -536,3 +538,197 @@ func TestEncodingStruct(t *testing.T) {
 		t.Fatal("bad")
 	}
 }
+
+func TestEncodingFixedPoint(t *testing.T) {
+	cases := []struct {
+		typ string
+		val interface{}
+		res string
+		dec string
+	}{
+		{"ufixed128x18", "1.5", "00000000000000000000000000000000000000000000000014d1120d7b160000", "1.5"},
+		{"fixed128x2", decimal.RequireFromString("-0.01"), "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", "-0.01"},
+		{"fixed8x1", 12, "0000000000000000000000000000000000000000000000000000000000000078", "12"},
+	}
+	for _, c := range cases {
+		typ := MustNewType(c.typ)
+
+		res, err := Encode(c.val, typ)
+		assert.NoError(t, err)
+		assert.Equal(t, encodeHex(res), "0x"+c.res)
+
+		val, err := Decode(typ, res)
+		assert.NoError(t, err)
+		assert.Equal(t, val.(decimal.Decimal).String(), c.dec)
+	}
+
+	// more decimals than the type
+	_, err := Encode("1.001", MustNewType("ufixed128x2"))
+	assert.Error(t, err)
+
+	// out of range
+	_, err = Encode("-1", MustNewType("ufixed128x2"))
+	assert.Error(t, err)
+	_, err = Encode("12.8", MustNewType("fixed8x1"))
+	assert.Error(t, err)
+}
+
+func TestEncodingFunction(t *testing.T) {
+	typ := MustNewType("tuple(function f)")
+	f := FunctionRef{Address: web3.Address{0x1}, Selector: [4]byte{0xa9, 0x05, 0x9c, 0xbb}}
+
+	res, err := Encode(map[string]interface{}{"f": f}, typ)
+	assert.NoError(t, err)
+	assert.Equal(t, encodeHex(res), "0x0100000000000000000000000000000000000000a9059cbb0000000000000000")
+
+	val, err := Decode(typ, res)
+	assert.NoError(t, err)
+	assert.Equal(t, val.(map[string]interface{})["f"], f)
+}
+
+func TestEncodingStructTags(t *testing.T) {
+	typ := MustNewType("tuple(address owner, tuple(uint64 id, string tag)[] items, tuple(bool ok) meta, uint256 total)")
+
+	type Item struct {
+		ID  uint64
+		Tag string `abi:"tag"`
+	}
+	type Meta struct {
+		Valid bool `abi:"ok"`
+	}
+	type Obj struct {
+		Account web3.Address `abi:"owner"`
+		Items   []Item
+		Meta    *Meta
+		Total   uint64
+		Ignored string `abi:"-"`
+	}
+	obj := Obj{
+		Account: web3.Address{0x1},
+		Items:   []Item{{ID: 1, Tag: "a"}, {ID: 2, Tag: "b"}},
+		Meta:    &Meta{Valid: true},
+		Total:   10,
+		Ignored: "x",
+	}
+
+	encoded, err := typ.Encode(&obj)
+	assert.NoError(t, err)
+
+	// the encoding is the same as the one of the map
+	encodedMap, err := typ.Encode(map[string]interface{}{
+		"owner": web3.Address{0x1},
+		"items": []map[string]interface{}{
+			{"id": uint64(1), "tag": "a"},
+			{"id": uint64(2), "tag": "b"},
+		},
+		"meta":  map[string]interface{}{"ok": true},
+		"total": big.NewInt(10),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, encoded, encodedMap)
+
+	var obj2 Obj
+	assert.NoError(t, typ.DecodeStruct(encoded, &obj2))
+
+	obj.Ignored = ""
+	assert.Equal(t, obj, obj2)
+
+	// the elements of the tuple without field are ignored
+	// unless the decoding is strict
+	var obj3 struct {
+		Owner web3.Address
+		Total *big.Int
+	}
+	assert.NoError(t, typ.DecodeStruct(encoded, &obj3))
+	assert.Equal(t, obj3.Owner, web3.Address{0x1})
+	assert.Equal(t, obj3.Total, big.NewInt(10))
+	assert.Error(t, typ.DecodeStructStrict(encoded, &obj3))
+
+	// the numbers that overflow the field fail
+	var obj4 struct {
+		Owner [20]byte
+		Items []Item
+		Meta  Meta
+		Total uint8
+	}
+	encoded, err = typ.Encode(map[string]interface{}{
+		"owner": web3.Address{0x1},
+		"items": []map[string]interface{}{},
+		"meta":  map[string]interface{}{"ok": true},
+		"total": big.NewInt(256),
+	})
+	assert.NoError(t, err)
+	assert.Error(t, typ.DecodeStruct(encoded, &obj4))
+}
+
+func TestEncodingInvalidBytes(t *testing.T) {
+	cases := []struct {
+		typ string
+		val interface{}
+	}{
+		{"address", "0x0100000000000000000000000000000000000000"},
+		{"address", []int{1, 2}},
+		{"bytes", "abc"},
+	}
+	for _, c := range cases {
+		_, err := Encode(c.val, MustNewType(c.typ))
+		assert.Error(t, err)
+	}
+
+	// byte arrays and slices are valid
+	res, err := Encode(web3.Address{0x1}, MustNewType("address"))
+	assert.NoError(t, err)
+	assert.Equal(t, encodeHex(res), "0x0000000000000000000000000100000000000000000000000000000000000000")
+
+	_, err = Encode([]byte{0x1}, MustNewType("bytes"))
+	assert.NoError(t, err)
+}
+
+func TestEncodingStructTagsCase(t *testing.T) {
+	typ := MustNewType("tuple(address owner, uint256 totalSupply)")
+
+	// the tags match the names of the elements without case
+	type Obj struct {
+		Account web3.Address `abi:"Owner"`
+		Supply  *big.Int     `abi:"TOTALSUPPLY"`
+	}
+	obj := Obj{Account: web3.Address{0x1}, Supply: big.NewInt(10)}
+
+	encoded, err := typ.Encode(&obj)
+	assert.NoError(t, err)
+
+	var obj2 Obj
+	assert.NoError(t, typ.DecodeStructStrict(encoded, &obj2))
+	assert.Equal(t, obj2, obj)
+}
+
+func TestEncodingStructTagsMapstructure(t *testing.T) {
+	typ := MustNewType("tuple(address owner, uint256 totalSupply, uint8 decimals)")
+
+	// the mapstructure tags are used if there is no abi tag
+	type Obj struct {
+		Account  web3.Address `mapstructure:"owner"`
+		Supply   *big.Int     `mapstructure:"totalSupply,omitempty"`
+		Decimals uint8        `abi:"decimals" mapstructure:"-"`
+	}
+	obj := Obj{Account: web3.Address{0x1}, Supply: big.NewInt(10), Decimals: 18}
+
+	encoded, err := typ.Encode(&obj)
+	assert.NoError(t, err)
+
+	var obj2 Obj
+	assert.NoError(t, typ.DecodeStructStrict(encoded, &obj2))
+	assert.Equal(t, obj2, obj)
+
+	// the fields skipped with the mapstructure tag have no element
+	var obj3 struct {
+		Owner    web3.Address
+		Supply   *big.Int `mapstructure:"totalSupply"`
+		Decimals uint8    `mapstructure:"-"`
+	}
+	assert.NoError(t, typ.DecodeStruct(encoded, &obj3))
+	assert.Equal(t, obj3.Owner, web3.Address{0x1})
+	assert.Equal(t, obj3.Supply, big.NewInt(10))
+	assert.Equal(t, obj3.Decimals, uint8(0))
+	assert.Error(t, typ.DecodeStructStrict(encoded, &obj3))
+}
@@ -0,0 +1,260 @@
+package abi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// argumentJSON is the json format of an argument of the abi
+type argumentJSON struct {
+	Name         string          `json:"name"`
+	Type         string          `json:"type"`
+	InternalType string          `json:"internalType,omitempty"`
+	Indexed      *bool           `json:"indexed,omitempty"`
+	Components   []*argumentJSON `json:"components,omitempty"`
+}
+
+// fieldJSON is the json format of an entry of the abi
+type fieldJSON struct {
+	Type            string           `json:"type"`
+	Name            string           `json:"name,omitempty"`
+	Inputs          []*argumentJSON  `json:"inputs"`
+	Outputs         *[]*argumentJSON `json:"outputs,omitempty"`
+	StateMutability string           `json:"stateMutability,omitempty"`
+	Anonymous       *bool            `json:"anonymous,omitempty"`
+}
+
+// canonicalType returns the type in the format of the json abi, where
+// the tuples are 'tuple' and the elements are in the components
+func canonicalType(t *Type) string {
+	switch t.kind {
+	case KindTuple:
+		return "tuple"
+	case KindSlice:
+		return canonicalType(t.elem) + "[]"
+	case KindArray:
+		return fmt.Sprintf("%s[%d]", canonicalType(t.elem), t.size)
+	default:
+		return t.raw
+	}
+}
+
+func encodeArgument(name string, t *Type, internalType string) *argumentJSON {
+	arg := &argumentJSON{
+		Name:         name,
+		Type:         canonicalType(t),
+		InternalType: internalType,
+	}
+
+	// the components of a tuple or an array of tuples
+	for t.kind == KindSlice || t.kind == KindArray {
+		t = t.elem
+	}
+	if t.kind == KindTuple {
+		arg.Components = encodeArguments(t, false)
+	}
+	return arg
+}
+
+func encodeArguments(t *Type, event bool) []*argumentJSON {
+	res := []*argumentJSON{}
+	if t == nil {
+		return res
+	}
+	for _, elem := range t.tuple {
+		arg := encodeArgument(elem.Name, elem.Elem, elem.InternalType)
+		if event {
+			indexed := elem.Indexed
+			arg.Indexed = &indexed
+		}
+		res = append(res, arg)
+	}
+	return res
+}
+
+func stateMutability(m *Method) string {
+	if m.StateMutability != "" {
+		return m.StateMutability
+	}
+	if m.Payable {
+		return "payable"
+	}
+	if m.Const {
+		return "view"
+	}
+	return "nonpayable"
+}
+
+// MarshalJSON implements the json.Marshaler interface. The type is
+// encoded as an argument without name (i.e. {"type": "uint256"}).
+func (t *Type) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encodeArgument("", t, ""))
+}
+
+// MarshalJSON implements the json.Marshaler interface. The entries
+// are sorted by type and name so that the output is deterministic.
+func (a *ABI) MarshalJSON() ([]byte, error) {
+	fields := []*fieldJSON{}
+
+	if a.Constructor != nil {
+		fields = append(fields, &fieldJSON{
+			Type:            "constructor",
+			Inputs:          encodeArguments(a.Constructor.Inputs, false),
+			StateMutability: stateMutability(a.Constructor),
+		})
+	}
+	if a.Fallback != nil {
+		fields = append(fields, &fieldJSON{
+			Type:            "fallback",
+			Inputs:          []*argumentJSON{},
+			StateMutability: stateMutability(a.Fallback),
+		})
+	}
+	if a.Receive != nil {
+		fields = append(fields, &fieldJSON{
+			Type:            "receive",
+			Inputs:          []*argumentJSON{},
+			StateMutability: "payable",
+		})
+	}
+	for _, m := range a.sortedMethods() {
+		outputs := encodeArguments(m.Outputs, false)
+		fields = append(fields, &fieldJSON{
+			Type:            "function",
+			Name:            m.Name,
+			Inputs:          encodeArguments(m.Inputs, false),
+			Outputs:         &outputs,
+			StateMutability: stateMutability(m),
+		})
+	}
+	for _, e := range a.sortedEvents() {
+		anonymous := e.Anonymous
+		fields = append(fields, &fieldJSON{
+			Type:      "event",
+			Name:      e.Name,
+			Inputs:    encodeArguments(e.Inputs, true),
+			Anonymous: &anonymous,
+		})
+	}
+	for _, e := range a.sortedErrors() {
+		fields = append(fields, &fieldJSON{
+			Type:   "error",
+			Name:   e.Name,
+			Inputs: encodeArguments(e.Inputs, false),
+		})
+	}
+	return json.Marshal(fields)
+}
+
+// sortedMethods returns the methods sorted by name, with the
+// overloads in order of declaration
+func (a *ABI) sortedMethods() []*Method {
+	names := make([]string, 0, len(a.Methods))
+	for name := range a.Methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := []*Method{}
+	for _, name := range names {
+		res = append(res, a.Overloads(name)...)
+	}
+	return res
+}
+
+func (a *ABI) sortedEvents() []*Event {
+	res := make([]*Event, 0, len(a.Events))
+	for _, e := range a.Events {
+		res = append(res, e)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+func (a *ABI) sortedErrors() []*Error {
+	res := make([]*Error, 0, len(a.Errors))
+	for _, e := range a.Errors {
+		res = append(res, e)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// humanReadableType returns the type with the names of the
+// tuple elements (i.e. 'tuple(uint256 a, address b)[]')
+func humanReadableType(t *Type) string {
+	switch t.kind {
+	case KindTuple:
+		return "tuple(" + humanReadableArgs(t, false) + ")"
+	case KindSlice:
+		return humanReadableType(t.elem) + "[]"
+	case KindArray:
+		return fmt.Sprintf("%s[%d]", humanReadableType(t.elem), t.size)
+	default:
+		return t.raw
+	}
+}
+
+func humanReadableArgs(t *Type, event bool) string {
+	if t == nil {
+		return ""
+	}
+	args := []string{}
+	for _, elem := range t.tuple {
+		arg := humanReadableType(elem.Elem)
+		if event && elem.Indexed {
+			arg += " indexed"
+		}
+		if elem.Name != "" {
+			arg += " " + elem.Name
+		}
+		args = append(args, arg)
+	}
+	return strings.Join(args, ", ")
+}
+
+func humanReadableMethod(prefix string, m *Method) string {
+	str := prefix + "(" + humanReadableArgs(m.Inputs, false) + ")"
+	if mutability := stateMutability(m); mutability != "nonpayable" {
+		str += " " + mutability
+	}
+	if m.Outputs != nil && len(m.Outputs.tuple) != 0 {
+		str += " returns (" + humanReadableArgs(m.Outputs, false) + ")"
+	}
+	return str
+}
+
+// HumanReadable returns the abi in the human readable format,
+// it is the inverse of NewABIFromList
+func (a *ABI) HumanReadable() []string {
+	res := []string{}
+	if a.Constructor != nil {
+		res = append(res, humanReadableMethod("constructor", a.Constructor))
+	}
+	if a.Fallback != nil {
+		res = append(res, humanReadableMethod("fallback", a.Fallback))
+	}
+	if a.Receive != nil {
+		res = append(res, humanReadableMethod("receive", a.Receive))
+	}
+	for _, m := range a.sortedMethods() {
+		res = append(res, humanReadableMethod("function "+m.Name, m))
+	}
+	for _, e := range a.sortedEvents() {
+		str := "event " + e.Name + "(" + humanReadableArgs(e.Inputs, true) + ")"
+		if e.Anonymous {
+			str += " anonymous"
+		}
+		res = append(res, str)
+	}
+	for _, e := range a.sortedErrors() {
+		res = append(res, "error "+e.Name+"("+humanReadableArgs(e.Inputs, false)+")")
+	}
+	return res
+}
@@ -0,0 +1,144 @@
+package abi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const marshalTestAbi = `[
+	{
+		"type": "constructor",
+		"inputs": [{"name": "owner", "type": "address"}],
+		"stateMutability": "payable"
+	},
+	{"type": "fallback", "stateMutability": "nonpayable"},
+	{"type": "receive", "stateMutability": "payable"},
+	{
+		"type": "function",
+		"name": "submit",
+		"inputs": [
+			{
+				"name": "orders",
+				"type": "tuple[]",
+				"internalType": "struct Exchange.Order[]",
+				"components": [
+					{"name": "maker", "type": "address", "internalType": "address"},
+					{"name": "amounts", "type": "uint256[2]", "internalType": "uint256[2]"}
+				]
+			}
+		],
+		"outputs": [{"name": "", "type": "bool", "internalType": "bool"}],
+		"stateMutability": "nonpayable"
+	},
+	{
+		"type": "function",
+		"name": "balanceOf",
+		"inputs": [{"name": "owner", "type": "address"}],
+		"outputs": [{"name": "", "type": "uint256"}],
+		"stateMutability": "view"
+	},
+	{
+		"type": "function",
+		"name": "balanceOf",
+		"inputs": [{"name": "owner", "type": "address"}, {"name": "id", "type": "uint256"}],
+		"outputs": [{"name": "", "type": "uint256"}],
+		"stateMutability": "pure"
+	},
+	{
+		"type": "event",
+		"name": "Transfer",
+		"inputs": [
+			{"name": "from", "type": "address", "indexed": true},
+			{"name": "to", "type": "address", "indexed": true},
+			{"name": "amount", "type": "uint256", "indexed": false}
+		],
+		"anonymous": false
+	},
+	{
+		"type": "event",
+		"name": "Log",
+		"inputs": [{"name": "data", "type": "bytes", "indexed": false}],
+		"anonymous": true
+	},
+	{
+		"type": "error",
+		"name": "Unauthorized",
+		"inputs": [{"name": "account", "type": "address"}]
+	}
+]`
+
+func TestAbi_MarshalJSON(t *testing.T) {
+	abi, err := NewABI(marshalTestAbi)
+	assert.NoError(t, err)
+
+	data, err := json.Marshal(abi)
+	assert.NoError(t, err)
+
+	abi2, err := NewABI(string(data))
+	assert.NoError(t, err)
+	assert.Equal(t, abi, abi2)
+
+	// the output is deterministic
+	data2, err := json.Marshal(abi2)
+	assert.NoError(t, err)
+	assert.Equal(t, data, data2)
+
+	// the components and internal types are kept
+	var fields []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &fields))
+
+	var submit map[string]interface{}
+	for _, field := range fields {
+		if field["name"] == "submit" {
+			submit = field
+		}
+	}
+	input := submit["inputs"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, input["type"], "tuple[]")
+	assert.Equal(t, input["internalType"], "struct Exchange.Order[]")
+	assert.Len(t, input["components"], 2)
+}
+
+func TestType_MarshalJSON(t *testing.T) {
+	typ := MustNewType("tuple(address a, uint256[] b)[2]")
+
+	data, err := json.Marshal(typ)
+	assert.NoError(t, err)
+	assert.Equal(t, string(data), `{"name":"","type":"tuple[2]","components":[{"name":"a","type":"address"},{"name":"b","type":"uint256[]"}]}`)
+
+	var arg *ArgumentStr
+	assert.NoError(t, json.Unmarshal(data, &arg))
+
+	typ2, err := NewTypeFromArgument(arg)
+	assert.NoError(t, err)
+	assert.Equal(t, typ.String(), typ2.String())
+}
+
+func TestAbi_HumanReadableRoundTrip(t *testing.T) {
+	abi, err := NewABI(marshalTestAbi)
+	assert.NoError(t, err)
+
+	list := abi.HumanReadable()
+	assert.Equal(t, list, []string{
+		"constructor(address owner) payable",
+		"fallback()",
+		"receive() payable",
+		"function balanceOf(address owner) view returns (uint256)",
+		"function balanceOf(address owner, uint256 id) pure returns (uint256)",
+		"function submit(tuple(address maker, uint256[2] amounts)[] orders) returns (bool)",
+		"event Log(bytes data) anonymous",
+		"event Transfer(address indexed from, address indexed to, uint256 amount)",
+		"error Unauthorized(address account)",
+	})
+
+	abi2, err := NewABIFromList(list)
+	assert.NoError(t, err)
+	assert.Equal(t, abi2.HumanReadable(), list)
+
+	assert.True(t, abi2.Methods["balanceOf"].Const)
+	assert.True(t, abi2.Constructor.Payable)
+	assert.True(t, abi2.Events["Log"].Anonymous)
+	assert.Equal(t, abi2.Methods["submit"].Sig(), abi.Methods["submit"].Sig())
+}
@@ -0,0 +1,160 @@
+package abi
+
+import (
+	"fmt"
+	"reflect"
+
+	web3 "github.com/mover-code/golang-web3"
+)
+
+// EncodePacked encodes a value with the non-standard packed mode of
+// Solidity (abi.encodePacked). If the type is a tuple, the elements are
+// concatenated. Nested tuples and dynamic types inside arrays are not
+// supported since they are ambiguous in this mode.
+func EncodePacked(v interface{}, t *Type) ([]byte, error) {
+	val := reflect.ValueOf(v)
+	if t.kind != KindTuple {
+		return encodePacked(val, t)
+	}
+
+	values, err := tupleValues(val, t)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []byte
+	for i, elem := range t.tuple {
+		res, err := encodePacked(values[i], elem.Elem)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, res...)
+	}
+	return ret, nil
+}
+
+// SoliditySHA3 returns the keccak256 hash of the packed encoding of a
+// value, which is keccak256(abi.encodePacked(...)) in Solidity
+func SoliditySHA3(v interface{}, t *Type) (web3.Hash, error) {
+	data, err := EncodePacked(v, t)
+	if err != nil {
+		return web3.Hash{}, err
+	}
+
+	var dst web3.Hash
+	k := acquireKeccak()
+	k.Write(data)
+	k.Sum(dst[:0])
+	releaseKeccak(k)
+	return dst, nil
+}
+
+func encodePacked(v reflect.Value, t *Type) ([]byte, error) {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	switch t.kind {
+	case KindSlice, KindArray:
+		return encodePackedArray(v, t)
+
+	case KindString:
+		if v.Kind() != reflect.String {
+			return nil, encodeErr(v, "string")
+		}
+		return []byte(v.String()), nil
+
+	case KindBytes:
+		if v.Kind() == reflect.Array {
+			v = convertArrayToBytes(v)
+		}
+		if v.Kind() != reflect.Slice {
+			return nil, encodeErr(v, "bytes")
+		}
+		return append([]byte{}, v.Bytes()...), nil
+
+	case KindBool:
+		if v.Kind() != reflect.Bool {
+			return nil, encodeErr(v, "bool")
+		}
+		if v.Bool() {
+			return []byte{1}, nil
+		}
+		return []byte{0}, nil
+
+	case KindAddress:
+		res, err := encodeAddress(v)
+		if err != nil {
+			return nil, err
+		}
+		return res[12:], nil
+
+	case KindInt, KindUInt:
+		// two's complement with the size of the type
+		res, err := encodeNum(v)
+		if err != nil {
+			return nil, err
+		}
+		return res[32-t.size/8:], nil
+
+	case KindFixedBytes:
+		res, err := encodeFixedBytes(v)
+		if err != nil {
+			return nil, err
+		}
+		return res[:t.size], nil
+
+	case KindFunction:
+		res, err := encodeFunction(v)
+		if err != nil {
+			return nil, err
+		}
+		return res[:24], nil
+
+	case KindFixedPoint:
+		res, err := encodeFixedPoint(v, t)
+		if err != nil {
+			return nil, err
+		}
+		return res[32-t.size/8:], nil
+
+	default:
+		return nil, fmt.Errorf("packed encoding not available for type '%s'", t.kind)
+	}
+}
+
+// encodePackedArray encodes the elements of the array padded
+// to 32 bytes and without the length of the array
+func encodePackedArray(v reflect.Value, t *Type) ([]byte, error) {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Array && v.Kind() != reflect.Slice {
+		return nil, encodeErr(v, t.kind.String())
+	}
+	if t.kind == KindArray && t.size != v.Len() {
+		return nil, fmt.Errorf("array len incompatible")
+	}
+
+	elem := t.elem
+	switch elem.kind {
+	case KindTuple, KindString, KindBytes:
+		return nil, fmt.Errorf("packed encoding not available for arrays of '%s'", elem.raw)
+	}
+
+	var ret []byte
+	for i := 0; i < v.Len(); i++ {
+		var res []byte
+		var err error
+		if elem.kind == KindSlice || elem.kind == KindArray {
+			res, err = encodePackedArray(v.Index(i), elem)
+		} else {
+			res, err = encode(v.Index(i), elem)
+		}
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, res...)
+	}
+	return ret, nil
+}
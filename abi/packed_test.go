@@ -0,0 +1,72 @@
+package abi
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	web3 "github.com/mover-code/golang-web3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodePacked(t *testing.T) {
+	cases := []struct {
+		typ   string
+		value interface{}
+		res   string
+	}{
+		{
+			// example of the solidity documentation
+			"tuple(int16 a, bytes1 b, uint16 c, string d)",
+			[]interface{}{int16(-1), [1]byte{0x42}, uint16(3), "Hello, world!"},
+			"ffff42000348656c6c6f2c20776f726c6421",
+		},
+		{
+			"tuple(address a, bool b, bytes c)",
+			[]interface{}{web3.Address{0x1}, true, []byte{0x1, 0x2}},
+			"0100000000000000000000000000000000000000" + "01" + "0102",
+		},
+		{
+			"int256",
+			big.NewInt(-2),
+			"fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffe",
+		},
+		{
+			// the elements of the arrays are padded
+			"uint8[]",
+			[]uint8{1, 2},
+			"0000000000000000000000000000000000000000000000000000000000000001" +
+				"0000000000000000000000000000000000000000000000000000000000000002",
+		},
+		{
+			"tuple(uint8 a, address[1] b)",
+			map[string]interface{}{
+				"a": uint8(1),
+				"b": [1]web3.Address{{0x1}},
+			},
+			"01" + "0000000000000000000000000100000000000000000000000000000000000000",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run("", func(t *testing.T) {
+			res, err := EncodePacked(c.value, MustNewType(c.typ))
+			assert.NoError(t, err)
+			assert.Equal(t, hex.EncodeToString(res), c.res)
+		})
+	}
+}
+
+func TestEncodePacked_Unsupported(t *testing.T) {
+	_, err := EncodePacked([]string{"a"}, MustNewType("string[]"))
+	assert.Error(t, err)
+
+	_, err = EncodePacked([]interface{}{[]interface{}{uint8(1)}}, MustNewType("tuple(tuple(uint8 a)[] b)"))
+	assert.Error(t, err)
+}
+
+func TestSoliditySHA3(t *testing.T) {
+	hash, err := SoliditySHA3([]interface{}{"hello"}, MustNewType("tuple(string a)"))
+	assert.NoError(t, err)
+	assert.Equal(t, hash.String(), "0x1c8aff950685c2ed4bc3174f3472287b56d9517b9c948127319a09a7a36deac8")
+}
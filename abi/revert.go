@@ -0,0 +1,135 @@
+package abi
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var (
+	// revertReasonError is the error used by revert and require with a reason
+	revertReasonError = &Error{Name: "Error", Inputs: MustNewType("tuple(string reason)")}
+
+	// revertPanicError is the error used by failed assertions and internal errors
+	revertPanicError = &Error{Name: "Panic", Inputs: MustNewType("tuple(uint256 code)")}
+)
+
+var panicReasons = map[uint64]string{
+	0x00: "generic panic",
+	0x01: "assert(false)",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "enum overflow",
+	0x22: "invalid encoded storage byte array accessed",
+	0x31: "out-of-bounds array access; popping on an empty array",
+	0x32: "out-of-bounds access of an array or bytesN",
+	0x41: "out of memory",
+	0x51: "uninitialized function",
+}
+
+// RevertError is the error of an execution that reverted
+type RevertError struct {
+	// Data is the raw revert data
+	Data []byte
+
+	// Name is the name of the error. It is 'Error' for reverts with a reason,
+	// 'Panic' for failed assertions or the name of the custom error. It is empty
+	// if there is no revert data or the error is unknown.
+	Name string
+
+	// Args are the decoded arguments of the error
+	Args map[string]interface{}
+
+	// Reason is the reason of the revert for Error(string)
+	Reason string
+
+	// PanicCode is the code of Panic(uint256)
+	PanicCode *big.Int
+
+	inputs *Type
+
+	// builtin is the builtin error that matched the data, if any
+	builtin *Error
+}
+
+// Error implements the error interface
+func (r *RevertError) Error() string {
+	switch {
+	case r.builtin == revertReasonError:
+		return "execution reverted: " + r.Reason
+
+	case r.builtin == revertPanicError && r.PanicCode != nil:
+		msg := fmt.Sprintf("execution reverted: panic code 0x%x", r.PanicCode)
+		if r.PanicCode.IsUint64() {
+			if reason, ok := panicReasons[r.PanicCode.Uint64()]; ok {
+				msg += " (" + reason + ")"
+			}
+		}
+		return msg
+
+	case r.Name != "":
+		args := []string{}
+		for _, elem := range r.inputs.TupleElems() {
+			args = append(args, fmt.Sprintf("%s: %v", elem.Name, r.Args[elem.Name]))
+		}
+		return fmt.Sprintf("execution reverted: %s(%s)", r.Name, strings.Join(args, ", "))
+
+	case len(r.Data) != 0:
+		return "execution reverted: 0x" + hex.EncodeToString(r.Data)
+
+	default:
+		return "execution reverted"
+	}
+}
+
+// DecodeRevert decodes the revert data of an execution. Besides Error(string) and
+// Panic(uint256), it decodes the custom errors of the abi, which can be nil.
+func DecodeRevert(a *ABI, data []byte) (*RevertError, error) {
+	res := &RevertError{
+		Data: data,
+	}
+	if len(data) < 4 {
+		return res, nil
+	}
+
+	// the builtin errors go first, custom errors with the same
+	// signature decode the same way
+	errs := []*Error{revertReasonError, revertPanicError}
+	if a != nil {
+		for _, e := range a.Errors {
+			errs = append(errs, e)
+		}
+	}
+
+	var selected *Error
+	for _, e := range errs {
+		if e.Match(data) {
+			selected = e
+			break
+		}
+	}
+	if selected == nil {
+		// unknown error
+		return res, nil
+	}
+
+	args, err := selected.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode error %s: %v", selected.Name, err)
+	}
+
+	res.Name = selected.Name
+	res.Args = args
+	res.inputs = selected.Inputs
+
+	switch selected {
+	case revertReasonError:
+		res.builtin = selected
+		res.Reason, _ = args["reason"].(string)
+	case revertPanicError:
+		res.builtin = selected
+		res.PanicCode, _ = args["code"].(*big.Int)
+	}
+	return res, nil
+}
@@ -0,0 +1,98 @@
+package abi
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	web3 "github.com/mover-code/golang-web3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeRevert(t *testing.T) {
+	abi, err := NewABI(`[
+		{
+			"type": "error",
+			"name": "InsufficientBalance",
+			"inputs": [
+				{"name": "available", "type": "uint256"},
+				{"name": "owner", "type": "address"}
+			]
+		}
+	]`)
+	assert.NoError(t, err)
+	assert.Equal(t, abi.Errors["InsufficientBalance"].Sig(), "InsufficientBalance(uint256,address)")
+
+	hexToBytes := func(str string) []byte {
+		buf, err := hex.DecodeString(str)
+		assert.NoError(t, err)
+		return buf
+	}
+
+	t.Run("Reason", func(t *testing.T) {
+		// revert("not enough")
+		data := hexToBytes("08c379a0" +
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+			"000000000000000000000000000000000000000000000000000000000000000a" +
+			"6e6f7420656e6f75676800000000000000000000000000000000000000000000")
+
+		res, err := DecodeRevert(nil, data)
+		assert.NoError(t, err)
+		assert.Equal(t, res.Name, "Error")
+		assert.Equal(t, res.Reason, "not enough")
+		assert.Equal(t, res.Error(), "execution reverted: not enough")
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		data := hexToBytes("4e487b71" +
+			"0000000000000000000000000000000000000000000000000000000000000011")
+
+		res, err := DecodeRevert(nil, data)
+		assert.NoError(t, err)
+		assert.Equal(t, res.Name, "Panic")
+		assert.Equal(t, res.PanicCode, big.NewInt(0x11))
+		assert.Equal(t, res.Error(), "execution reverted: panic code 0x11 (arithmetic underflow or overflow)")
+	})
+
+	t.Run("Custom", func(t *testing.T) {
+		e := abi.Errors["InsufficientBalance"]
+		args, err := Encode([]interface{}{big.NewInt(10), web3.Address{0x1}}, e.Inputs)
+		assert.NoError(t, err)
+
+		res, err := DecodeRevert(abi, append(e.ID(), args...))
+		assert.NoError(t, err)
+		assert.Equal(t, res.Name, "InsufficientBalance")
+		assert.Equal(t, res.Args["available"], big.NewInt(10))
+		assert.Equal(t, res.Error(), "execution reverted: InsufficientBalance(available: 10, owner: 0x0100000000000000000000000000000000000000)")
+
+		// the error is unknown without the abi
+		res, err = DecodeRevert(nil, append(e.ID(), args...))
+		assert.NoError(t, err)
+		assert.Equal(t, res.Name, "")
+	})
+
+	t.Run("CustomPanic", func(t *testing.T) {
+		// a custom error with the name of a builtin error
+		custom, err := NewABI(`[
+			{"type": "error", "name": "Panic", "inputs": [{"name": "message", "type": "string"}]}
+		]`)
+		assert.NoError(t, err)
+
+		e := custom.Errors["Panic"]
+		args, err := Encode([]interface{}{"boom"}, e.Inputs)
+		assert.NoError(t, err)
+
+		res, err := DecodeRevert(custom, append(e.ID(), args...))
+		assert.NoError(t, err)
+		assert.Equal(t, res.Name, "Panic")
+		assert.Nil(t, res.PanicCode)
+		assert.Equal(t, res.Args["message"], "boom")
+		assert.Equal(t, res.Error(), "execution reverted: Panic(message: boom)")
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		res, err := DecodeRevert(abi, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, res.Error(), "execution reverted")
+	})
+}
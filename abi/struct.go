@@ -0,0 +1,327 @@
+package abi
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mover-code/golang-web3"
+)
+
+// structFields are the fields of a struct by the name of the tuple
+// element. The name is either the 'abi' tag of the field, the
+// 'mapstructure' tag if there is no 'abi' tag or the name of the
+// field, all lowercase so that they match without case.
+type structFields struct {
+	tags  map[string]int
+	names map[string]int
+}
+
+func (s *structFields) find(name string) (int, bool) {
+	name = strings.ToLower(name)
+	if indx, ok := s.tags[name]; ok {
+		return indx, true
+	}
+	indx, ok := s.names[name]
+	return indx, ok
+}
+
+var structFieldsCache sync.Map
+
+func getStructFields(typ reflect.Type) *structFields {
+	if res, ok := structFieldsCache.Load(typ); ok {
+		return res.(*structFields)
+	}
+
+	res := &structFields{
+		tags:  map[string]int{},
+		names: map[string]int{},
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		tagValue := fieldTag(f)
+		if tagValue == "-" {
+			continue
+		}
+		if tagValue != "" {
+			tagValue = strings.ToLower(tagValue)
+			if _, ok := res.tags[tagValue]; !ok {
+				res.tags[tagValue] = i
+			}
+			continue
+		}
+
+		name := strings.ToLower(f.Name)
+		if _, ok := res.names[name]; !ok {
+			res.names[name] = i
+		}
+	}
+
+	structFieldsCache.Store(typ, res)
+	return res
+}
+
+// fieldTag returns the 'abi' tag of the field or the name
+// in the 'mapstructure' tag, which was used before
+func fieldTag(f reflect.StructField) string {
+	if tagValue, ok := f.Tag.Lookup("abi"); ok {
+		return tagValue
+	}
+	tagValue := f.Tag.Get("mapstructure")
+	if indx := strings.Index(tagValue, ","); indx != -1 {
+		tagValue = tagValue[:indx]
+	}
+	return tagValue
+}
+
+// discardValue is the destination of the tuple elements without field
+func discardValue() reflect.Value {
+	return reflect.New(reflect.TypeOf((*interface{})(nil)).Elem()).Elem()
+}
+
+// structField returns the field of the struct for the tuple element
+func structField(v reflect.Value, elem *TupleElem, indx int) (reflect.Value, error) {
+	name := elem.Name
+	if name == "" {
+		name = strconv.Itoa(indx)
+	}
+	i, ok := getStructFields(v.Type()).find(name)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("field for '%s' not found in %s", name, v.Type())
+	}
+	return v.Field(i), nil
+}
+
+// decodeInto decodes the input with the type directly into the value, which
+// is typically a struct. If strict is set, the elements of the tuples must
+// have a field in the struct, otherwise they are ignored.
+func decodeInto(t *Type, input []byte, v reflect.Value, strict bool) ([]byte, error) {
+	// allocate the pointers, *big.Int is set as a value
+	for v.Kind() == reflect.Ptr && v.Type() != bigIntT {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case t.kind == KindTuple && v.Kind() == reflect.Struct:
+		if len(input) < 32 && len(t.tuple) != 0 {
+			return nil, fmt.Errorf("incorrect length")
+		}
+		return decodeTupleInto(t, input, v, strict)
+
+	case (t.kind == KindSlice || t.kind == KindArray) && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array):
+		if len(input) < 32 {
+			return nil, fmt.Errorf("incorrect length")
+		}
+		if t.kind == KindArray {
+			return decodeArraySliceInto(t, input, t.size, v, strict)
+		}
+		length, err := readLength(input)
+		if err != nil {
+			return nil, err
+		}
+		return decodeArraySliceInto(t, input[32:], length, v, strict)
+	}
+
+	// basic types and generic destinations (i.e. interface{})
+	val, tail, err := decode(t, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := setValue(v, val); err != nil {
+		return nil, err
+	}
+	return tail, nil
+}
+
+func decodeTupleInto(t *Type, data []byte, v reflect.Value, strict bool) ([]byte, error) {
+	orig := data
+	origLen := len(orig)
+	for indx, arg := range t.tuple {
+		field, err := structField(v, arg, indx)
+		if err != nil {
+			if strict {
+				return nil, err
+			}
+			field = discardValue()
+		}
+
+		if len(data) < 32 {
+			return nil, fmt.Errorf("incorrect length")
+		}
+		entry := data
+		if arg.Elem.isDynamicType() {
+			offset, err := readOffset(data, origLen)
+			if err != nil {
+				return nil, err
+			}
+			entry = orig[offset:]
+		}
+
+		tail, err := decodeInto(arg.Elem, entry, field, strict)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode '%s': %v", arg.Name, err)
+		}
+
+		if !arg.Elem.isDynamicType() {
+			data = tail
+		} else {
+			data = data[32:]
+		}
+	}
+	return data, nil
+}
+
+func decodeArraySliceInto(t *Type, data []byte, size int, v reflect.Value, strict bool) ([]byte, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("size is lower than zero")
+	}
+	if 32*size > len(data) {
+		return nil, fmt.Errorf("size is too big")
+	}
+
+	if v.Kind() == reflect.Slice {
+		v.Set(reflect.MakeSlice(v.Type(), size, size))
+	} else if v.Len() != size {
+		return nil, fmt.Errorf("expected array of %d elements but found %d", size, v.Len())
+	}
+
+	orig := data
+	origLen := len(orig)
+	isDynamic := t.elem.isDynamicType()
+
+	for indx := 0; indx < size; indx++ {
+		entry := data
+		if isDynamic {
+			offset, err := readOffset(data, origLen)
+			if err != nil {
+				return nil, err
+			}
+			entry = orig[offset:]
+		}
+
+		tail, err := decodeInto(t.elem, entry, v.Index(indx), strict)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isDynamic {
+			data = tail
+		} else {
+			data = data[32:]
+		}
+	}
+	return data, nil
+}
+
+// setValue sets a decoded value in the destination. Numbers are
+// converted to any number type as long as they do not overflow.
+func setValue(v reflect.Value, val interface{}) error {
+	rv := reflect.ValueOf(val)
+	if rv.Type().AssignableTo(v.Type()) {
+		v.Set(rv)
+		return nil
+	}
+
+	if num, ok := toBigInt(rv); ok {
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if !num.IsInt64() || v.OverflowInt(num.Int64()) {
+				return fmt.Errorf("number %s overflows %s", num, v.Type())
+			}
+			v.SetInt(num.Int64())
+			return nil
+
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if !num.IsUint64() || v.OverflowUint(num.Uint64()) {
+				return fmt.Errorf("number %s overflows %s", num, v.Type())
+			}
+			v.SetUint(num.Uint64())
+			return nil
+
+		case reflect.Ptr:
+			if v.Type() == bigIntT {
+				v.Set(reflect.ValueOf(num))
+				return nil
+			}
+		}
+	}
+
+	// types with the same representation (i.e. web3.Address and [20]byte)
+	if rv.Kind() == v.Kind() && rv.Type().ConvertibleTo(v.Type()) {
+		v.Set(rv.Convert(v.Type()))
+		return nil
+	}
+	return fmt.Errorf("cannot decode %s into %s", rv.Type(), v.Type())
+}
+
+func toBigInt(v reflect.Value) (*big.Int, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(v.Uint()), true
+	case reflect.Ptr:
+		if v.Type() == bigIntT {
+			return v.Interface().(*big.Int), true
+		}
+	}
+	return nil, false
+}
+
+// ParseLogStruct parses an event log into a struct
+func ParseLogStruct(args *Type, log *web3.Log, out interface{}) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("expected a pointer to a struct but found %T", out)
+	}
+	v = v.Elem()
+
+	var indexed, nonIndexed []*TupleElem
+	for _, arg := range args.TupleElems() {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		} else {
+			nonIndexed = append(nonIndexed, arg)
+		}
+	}
+	if len(log.Topics) != len(indexed)+1 {
+		return fmt.Errorf("expected %d topics but found %d", len(indexed)+1, len(log.Topics))
+	}
+
+	// decode indexed fields
+	for indx, arg := range indexed {
+		val, err := ParseTopic(arg.Elem, log.Topics[indx+1])
+		if err != nil {
+			return err
+		}
+		field, err := structField(v, arg, indx)
+		if err != nil {
+			// the arguments without field are ignored
+			continue
+		}
+		if err := setValue(field, val); err != nil {
+			return fmt.Errorf("failed to decode '%s': %v", arg.Name, err)
+		}
+	}
+
+	// decode non indexed fields
+	if len(nonIndexed) > 0 {
+		if len(log.Data) == 0 {
+			return fmt.Errorf("empty input")
+		}
+		if _, err := decodeTupleInto(&Type{kind: KindTuple, tuple: nonIndexed}, log.Data, v, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/mover-code/golang-web3"
+	"github.com/shopspring/decimal"
 )
 
 func randomInt(min, max int) int {
@@ -125,7 +126,22 @@ func generateRandomType(t *Type) interface{} {
 		rand.Read(buf)
 		return buf
 
-	case KindFixedBytes, KindFunction:
+	case KindFunction:
+		f := FunctionRef{}
+		rand.Read(f.Address[:])
+		rand.Read(f.Selector[:])
+		return f
+
+	case KindFixedPoint:
+		b := make([]byte, t.size/8)
+		if t.signed {
+			rand.Read(b[1:])
+		} else {
+			rand.Read(b)
+		}
+		return decimal.NewFromBigInt(new(big.Int).SetBytes(b), -int32(t.decimals))
+
+	case KindFixedBytes:
 		buf := make([]byte, t.size)
 		rand.Read(buf)
 
@@ -90,6 +90,15 @@ func ParseTopic(t *Type, topic web3.Hash) (interface{}, error) {
 	case KindAddress:
 		return readAddr(topic[:])
 
+	case KindFixedBytes:
+		return readFixedBytes(t, topic[:])
+
+	case KindFunction:
+		return readFunctionType(t, topic[:])
+
+	case KindFixedPoint:
+		return readFixedPoint(t, topic[:]), nil
+
 	default:
 		return nil, fmt.Errorf("Topic parsing for type %s not supported", t.String())
 	}
@@ -111,10 +120,28 @@ func encodeTopic(t *Type, val reflect.Value) (web3.Hash, error) {
 	case KindAddress:
 		return encodeTopicAddress(val)
 
+	case KindFixedBytes:
+		return encodeTopicWord(encodeFixedBytes(val))
+
+	case KindFunction:
+		return encodeTopicWord(encodeFunction(val))
+
+	case KindFixedPoint:
+		return encodeTopicWord(encodeFixedPoint(val, t))
+
 	}
 	return web3.Hash{}, fmt.Errorf("not found")
 }
 
+// encodeTopicWord returns the topic of a value type encoded in one word
+func encodeTopicWord(b []byte, err error) (res web3.Hash, _ error) {
+	if err != nil {
+		return res, err
+	}
+	copy(res[:], b)
+	return res, nil
+}
+
 var topicTrue, topicFalse web3.Hash
 
 func init() {
@@ -9,6 +9,7 @@ import (
 	"github.com/mover-code/golang-web3/testutil"
 
 	web3 "github.com/mover-code/golang-web3"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -37,6 +38,18 @@ func TestTopicEncoding(t *testing.T) {
 			Type: "address",
 			Val:  web3.Address{0x1},
 		},
+		{
+			Type: "bytes4",
+			Val:  [4]byte{0x1, 0x2, 0x3, 0x4},
+		},
+		{
+			Type: "function",
+			Val:  FunctionRef{Address: web3.Address{0x1}, Selector: [4]byte{0x1}},
+		},
+		{
+			Type: "fixed128x2",
+			Val:  decimal.RequireFromString("-1.25"),
+		},
 	}
 
 	for _, c := range cases {
@@ -108,3 +121,41 @@ func TestIntegrationTopics(t *testing.T) {
 		}
 	}
 }
+
+func TestParseLogStruct(t *testing.T) {
+	evnt := MustNewEvent("event Transfer(address indexed from, address indexed to, uint256 value, bytes data)")
+
+	data, err := Encode([]interface{}{big.NewInt(100), []byte{0x1}}, MustNewType("tuple(uint256, bytes)"))
+	assert.NoError(t, err)
+
+	from, to := web3.Address{0x1}, web3.Address{0x2}
+	log := &web3.Log{
+		Topics: []web3.Hash{
+			evnt.ID(),
+			web3.BytesToHash(from[:]),
+			web3.BytesToHash(to[:]),
+		},
+		Data: data,
+	}
+
+	var transfer struct {
+		From    web3.Address
+		To      web3.Address
+		Amount  *big.Int `abi:"value"`
+		Payload []byte   `abi:"data"`
+	}
+	assert.NoError(t, evnt.ParseLogStruct(log, &transfer))
+	assert.Equal(t, transfer.From, from)
+	assert.Equal(t, transfer.To, to)
+	assert.Equal(t, transfer.Amount, big.NewInt(100))
+	assert.Equal(t, transfer.Payload, []byte{0x1})
+
+	// the arguments without field are ignored
+	var partial struct {
+		To    web3.Address
+		Value *big.Int
+	}
+	assert.NoError(t, evnt.ParseLogStruct(log, &partial))
+	assert.Equal(t, partial.To, to)
+	assert.Equal(t, partial.Value, big.NewInt(100))
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/mover-code/golang-web3"
+	"github.com/shopspring/decimal"
 )
 
 // batch of predefined reflect types
@@ -25,7 +26,8 @@ var (
 	addressT      = reflect.TypeOf(web3.Address{})
 	stringT       = reflect.TypeOf("")
 	dynamicBytesT = reflect.SliceOf(reflect.TypeOf(byte(0)))
-	functionT     = reflect.ArrayOf(24, reflect.TypeOf(byte(0)))
+	functionT     = reflect.TypeOf(FunctionRef{})
+	decimalT      = reflect.TypeOf(decimal.Decimal{})
 	tupleT        = reflect.TypeOf(map[string]interface{}{})
 	bigIntT       = reflect.TypeOf(new(big.Int))
 )
@@ -95,6 +97,26 @@ type TupleElem struct {
 	Name    string
 	Elem    *Type
 	Indexed bool
+
+	// InternalType is the type in the source code (i.e. 'struct Foo.Bar')
+	InternalType string
+}
+
+// FunctionRef is the value of a function type, the address
+// of the contract and the selector of the function
+type FunctionRef struct {
+	Address  web3.Address
+	Selector [4]byte
+}
+
+// Bytes returns the 24 bytes encoding of the function reference
+func (f FunctionRef) Bytes() []byte {
+	return append(append([]byte{}, f.Address[:]...), f.Selector[:]...)
+}
+
+// String implements the fmt.Stringer interface
+func (f FunctionRef) String() string {
+	return fmt.Sprintf("%s.0x%x", f.Address, f.Selector)
 }
 
 // Type is an ABI type
@@ -105,6 +127,10 @@ type Type struct {
 	raw   string
 	tuple []*TupleElem
 	t     reflect.Type
+
+	// decimals and signed are only used by the fixed point types
+	decimals int
+	signed   bool
 }
 
 func NewTupleType(inputs []*TupleElem) *Type {
@@ -129,6 +155,12 @@ func (t *Type) DecodeStruct(input []byte, out interface{}) error {
 	return DecodeStruct(t, input, out)
 }
 
+// DecodeStructStrict decodes an object using this type to the out param
+// and fails if any element of the tuples has no field
+func (t *Type) DecodeStructStrict(input []byte, out interface{}) error {
+	return DecodeStructStrict(t, input, out)
+}
+
 // Encode encodes an object using this type
 func (t *Type) Encode(v interface{}) ([]byte, error) {
 	return Encode(v, t)
@@ -149,6 +181,11 @@ func (t *Type) Size() int {
 	return t.size
 }
 
+// Decimals returns the number of decimals of a fixed point type
+func (t *Type) Decimals() int {
+	return t.decimals
+}
+
 // TupleElems returns the elems of the tuple
 func (t *Type) TupleElems() []*TupleElem {
 	return t.tuple
@@ -211,7 +248,27 @@ func NewTypeFromArgument(arg *ArgumentStr) (*Type, error) {
 	if err != nil {
 		return nil, err
 	}
-	return NewType(str)
+	t, err := NewType(str)
+	if err != nil {
+		return nil, err
+	}
+	setInternalTypes(t, arg.Components)
+	return t, nil
+}
+
+// setInternalTypes sets the internal types of the components
+// of a tuple (or an array of tuples)
+func setInternalTypes(t *Type, components []*ArgumentStr) {
+	for t.kind == KindSlice || t.kind == KindArray {
+		t = t.elem
+	}
+	if t.kind != KindTuple || len(t.tuple) != len(components) {
+		return
+	}
+	for indx, c := range components {
+		t.tuple[indx].InternalType = c.InternalType
+		setInternalTypes(t.tuple[indx].Elem, c.Components)
+	}
 }
 
 // NewType parses a type in string format
@@ -247,7 +304,7 @@ func getTypeSize(t *Type) int {
 	return 32
 }
 
-var typeRegexp = regexp.MustCompile("^([[:alpha:]]+)([[:digit:]]*)$")
+var typeRegexp = regexp.MustCompile("^([[:alpha:]]+?)([[:digit:]]*)(?:x([[:digit:]]+))?$")
 
 func expectedToken(t tokenType) error {
 	return fmt.Errorf("expected token %s", t.String())
@@ -385,15 +442,18 @@ func decodeSimpleType(str string) (*Type, error) {
 		ok = true
 	}
 
-	// Only int and uint need bytes for sure, 'bytes' may
-	// have or not, the rest dont have bytes
+	// Only int and uint need bytes for sure, 'bytes' and the
+	// fixed point types may have or not, the rest dont have bytes
 	if t == "int" || t == "uint" {
 		if !ok {
 			return nil, fmt.Errorf("int and uint expect bytes")
 		}
-	} else if t != "bytes" && ok {
+	} else if t != "bytes" && t != "fixed" && t != "ufixed" && ok {
 		return nil, fmt.Errorf("type %s does not expect bytes", t)
 	}
+	if match[2] != "" && t != "fixed" && t != "ufixed" {
+		return nil, fmt.Errorf("type %s does not expect decimals", t)
+	}
 
 	switch t {
 	case "uint":
@@ -456,6 +516,26 @@ func decodeSimpleType(str string) (*Type, error) {
 	case "function":
 		return &Type{kind: KindFunction, size: 24, t: functionT, raw: "function"}, nil
 
+	case "fixed", "ufixed":
+		// fixed and ufixed are aliases of fixed128x18 and ufixed128x18
+		decimals := 18
+		if !ok {
+			bytes = 128
+		} else if match[2] == "" {
+			return nil, fmt.Errorf("type %s expects the number of decimals", str)
+		} else {
+			if decimals, err = strconv.Atoi(match[2]); err != nil {
+				return nil, fmt.Errorf("failed to parse decimals '%s': %v", match[2], err)
+			}
+		}
+		if bytes < 8 || bytes > 256 || bytes%8 != 0 {
+			return nil, fmt.Errorf("fixed point size has to be M mod 8 between 8 and 256 but found %d", bytes)
+		}
+		if decimals > 80 {
+			return nil, fmt.Errorf("fixed point decimals have to be at most 80 but found %d", decimals)
+		}
+		return &Type{kind: KindFixedPoint, size: bytes, decimals: decimals, signed: t == "fixed", t: decimalT, raw: fmt.Sprintf("%s%dx%d", t, bytes, decimals)}, nil
+
 	default:
 		return nil, fmt.Errorf("unknown type '%s'", t)
 	}
@@ -266,6 +266,33 @@ func TestType(t *testing.T) {
 				tuple: []*TupleElem{},
 			},
 		},
+		{
+			s: "fixed128x18",
+			a: simpleType("fixed128x18"),
+			t: &Type{kind: KindFixedPoint, size: 128, decimals: 18, signed: true, t: decimalT, raw: "fixed128x18"},
+		},
+		{
+			s: "ufixed",
+			a: simpleType("ufixed"),
+			t: &Type{kind: KindFixedPoint, size: 128, decimals: 18, t: decimalT, raw: "ufixed128x18"},
+		},
+		{
+			s: "function",
+			a: simpleType("function"),
+			t: &Type{kind: KindFunction, size: 24, t: functionT, raw: "function"},
+		},
+		{
+			s:   "fixed7x1",
+			err: true,
+		},
+		{
+			s:   "ufixed128",
+			err: true,
+		},
+		{
+			s:   "int8x1",
+			err: true,
+		},
 		{
 			s:   "int[[",
 			err: true,
@@ -6,6 +6,8 @@ import (
 	"io/ioutil"
 	"path/filepath"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -67,6 +69,12 @@ func encodeSimpleArg(typ *abi.Type) string {
 	case abi.KindFixedBytes:
 		return fmt.Sprintf("[%d]byte", typ.Size())
 
+	case abi.KindFixedPoint:
+		return "decimal.Decimal"
+
+	case abi.KindFunction:
+		return "abi.FunctionRef"
+
 	case abi.KindBytes:
 		return "[]byte"
 
@@ -113,6 +121,76 @@ func tupleElems(tuple interface{}) []interface{} {
 	return res
 }
 
+// method is a contract method with the name of its Go function
+type method struct {
+	*abi.Method
+
+	// FuncName is the name of the generated function
+	FuncName string
+
+	// Key is the name used to call the method in the contract,
+	// it is the signature if the method is overloaded
+	Key string
+}
+
+// methods returns the methods of the abi sorted by name. Overloaded methods
+// get a numeric suffix after the first one (i.e. transfer, transfer0).
+func methods(a *abi.ABI) []*method {
+	names := []string{}
+	for name := range a.Methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := []*method{}
+	for _, name := range names {
+		overloads := a.Overloads(name)
+		for indx, m := range overloads {
+			elem := &method{
+				Method:   m,
+				FuncName: funcName(name),
+				Key:      name,
+			}
+			if len(overloads) > 1 {
+				elem.Key = m.Sig()
+			}
+			if indx != 0 {
+				elem.FuncName += strconv.Itoa(indx - 1)
+			}
+			res = append(res, elem)
+		}
+	}
+	return res
+}
+
+// usesKind returns true if any of the arguments of the methods is of the kind
+func usesKind(a *abi.ABI, kind abi.Kind) bool {
+	var check func(typ *abi.Type) bool
+	check = func(typ *abi.Type) bool {
+		if typ == nil {
+			return false
+		}
+		if typ.Kind() == kind {
+			return true
+		}
+		if typ.Kind() == abi.KindSlice || typ.Kind() == abi.KindArray {
+			return check(typ.Elem())
+		}
+		for _, elem := range typ.TupleElems() {
+			if check(elem.Elem) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, m := range a.MethodsBySignature {
+		if check(m.Inputs) || check(m.Outputs) {
+			return true
+		}
+	}
+	return false
+}
+
 func isNil(c interface{}) bool {
 	return c == nil || (reflect.ValueOf(c).Kind() == reflect.Ptr && reflect.ValueOf(c).IsNil())
 }
@@ -138,7 +216,7 @@ func gen(artifacts map[string]*compiler.Artifact, config *config, hash string) e
 
 	for name, artifact := range artifacts {
 		// parse abi
-		abi, err := abi.NewABI(artifact.Abi)
+		contractAbi, err := abi.NewABI(artifact.Abi)
 		if err != nil {
 			return err
 		}
@@ -147,7 +225,10 @@ func gen(artifacts map[string]*compiler.Artifact, config *config, hash string) e
 			"Ptr":      strings.ToLower(string(name[0])),
 			"Config":   config,
 			"Contract": artifact,
-			"Abi":      abi,
+			"Abi":      contractAbi,
+			"Methods":  methods(contractAbi),
+			"Decimal":  usesKind(contractAbi, abi.KindFixedPoint),
+			"Function": usesKind(contractAbi, abi.KindFunction),
 			"Name":     name,
 		}
 
@@ -181,8 +262,14 @@ import (
 	"math/big"
 
 	web3 "github.com/mover-code/golang-web3"
+{{- if .Function}}
+	"github.com/mover-code/golang-web3/abi"
+{{- end}}
 	"github.com/mover-code/golang-web3/contract"
 	"github.com/mover-code/golang-web3/jsonrpc"
+{{- if .Decimal}}
+	"github.com/shopspring/decimal"
+{{- end}}
 )
 
 var (
@@ -210,13 +297,13 @@ func ({{.Ptr}} *{{.Name}}) Contract() *contract.Contract {
 }
 
 // calls
-{{range $key, $value := .Abi.Methods}}{{if .Const}}
-// {{funcName $key}} calls the {{$key}} method in the solidity contract
-func ({{$.Ptr}} *{{$.Name}}) {{funcName $key}}({{range $index, $val := tupleElems .Inputs}}{{if .Name}}{{clean .Name}}{{else}}val{{$index}}{{end}} {{arg .}}, {{end}}block ...web3.BlockNumber) ({{range $index, $val := tupleElems .Outputs}}retval{{$index}} {{arg .}}, {{end}}err error) {
+{{range .Methods}}{{if .Const}}
+// {{.FuncName}} calls the {{.Name}} method in the solidity contract
+func ({{$.Ptr}} *{{$.Name}}) {{.FuncName}}({{range $index, $val := tupleElems .Inputs}}{{if .Name}}{{clean .Name}}{{else}}val{{$index}}{{end}} {{arg .}}, {{end}}block ...web3.BlockNumber) ({{range $index, $val := tupleElems .Outputs}}retval{{$index}} {{arg .}}, {{end}}err error) {
 	var out map[string]interface{}
 	{{ $length := tupleLen .Outputs }}{{ if ne $length 0 }}var ok bool{{ end }}
 
-	out, err = {{$.Ptr}}.c.Call("{{$key}}", web3.EncodeBlock(block...){{range $index, $val := tupleElems .Inputs}}, {{if .Name}}{{clean .Name}}{{else}}val{{$index}}{{end}}{{end}})
+	out, err = {{$.Ptr}}.c.Call("{{.Key}}", web3.EncodeBlock(block...){{range $index, $val := tupleElems .Inputs}}, {{if .Name}}{{clean .Name}}{{else}}val{{$index}}{{end}}{{end}})
 	if err != nil {
 		return
 	}
@@ -232,10 +319,10 @@ func ({{$.Ptr}} *{{$.Name}}) {{funcName $key}}({{range $index, $val := tupleElem
 }
 {{end}}{{end}}
 // txns
-{{range $key, $value := .Abi.Methods}}{{if not .Const}}
-// {{funcName $key}} sends a {{$key}} transaction in the solidity contract
-func ({{$.Ptr}} *{{$.Name}}) {{funcName $key}}({{range $index, $input := tupleElems .Inputs}}{{if $index}}, {{end}}{{clean .Name}} {{arg .}}{{end}}) *contract.Txn {
-	return {{$.Ptr}}.c.Txn("{{$key}}"{{range $index, $elem := tupleElems .Inputs}}, {{clean $elem.Name}}{{end}})
+{{range .Methods}}{{if not .Const}}
+// {{.FuncName}} sends a {{.Name}} transaction in the solidity contract
+func ({{$.Ptr}} *{{$.Name}}) {{.FuncName}}({{range $index, $input := tupleElems .Inputs}}{{if $index}}, {{end}}{{clean .Name}} {{arg .}}{{end}}) *contract.Txn {
+	return {{$.Ptr}}.c.Txn("{{.Key}}"{{range $index, $elem := tupleElems .Inputs}}, {{clean $elem.Name}}{{end}})
 }
 {{end}}{{end}}
 // events
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/mover-code/golang-web3/abi"
+	"github.com/mover-code/golang-web3/compiler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGen_UsesKind(t *testing.T) {
+	a := abi.MustNewABI(`[
+		{"type": "function", "name": "a", "inputs": [{"name": "x", "type": "uint256"}], "outputs": []},
+		{"type": "function", "name": "b", "inputs": [], "outputs": [
+			{"name": "y", "type": "tuple[]", "components": [{"name": "z", "type": "ufixed128x18"}]}
+		]}
+	]`)
+	assert.True(t, usesKind(a, abi.KindFixedPoint))
+	assert.False(t, usesKind(a, abi.KindFunction))
+}
+
+func TestGen_Imports(t *testing.T) {
+	output := t.TempDir()
+	artifacts := map[string]*compiler.Artifact{
+		"Simple": {
+			Abi: `[{"type": "function", "name": "a", "inputs": [{"name": "x", "type": "uint256"}], "outputs": []}]`,
+		},
+	}
+	assert.NoError(t, gen(artifacts, &config{Package: "simple", Output: output}, ""))
+
+	data, err := ioutil.ReadFile(filepath.Join(output, "simple.go"))
+	assert.NoError(t, err)
+
+	// the imports are only included if the types are used
+	assert.NotContains(t, string(data), "github.com/shopspring/decimal")
+	assert.NotContains(t, string(data), `"github.com/mover-code/golang-web3/abi"`)
+}
+
+// TestGen_Testdata checks that the code in testdata, which is built
+// with the rest of the module, is the output of the current generator
+func TestGen_Testdata(t *testing.T) {
+	// the source path used by scripts/build-artifacts.sh
+	source := "./abigen/testdata/testdata.abi"
+	raw := sha256.Sum256([]byte(source))
+
+	config := &config{Package: "testdata", Output: t.TempDir()}
+	artifacts, err := process(filepath.Join("testdata", "testdata.abi"), config)
+	assert.NoError(t, err)
+	assert.NoError(t, gen(artifacts, config, hex.EncodeToString(raw[:])))
+
+	for _, name := range []string{"testdata.go", "testdata_artifacts.go"} {
+		expected, err := ioutil.ReadFile(filepath.Join("testdata", name))
+		assert.NoError(t, err)
+
+		found, err := ioutil.ReadFile(filepath.Join(config.Output, name))
+		assert.NoError(t, err)
+		assert.Equal(t, string(found), string(expected))
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(config.Output, "testdata.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "price decimal.Decimal, callback abi.FunctionRef")
+}
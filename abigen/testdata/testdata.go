@@ -7,8 +7,10 @@ import (
 	"math/big"
 
 	web3 "github.com/mover-code/golang-web3"
+	"github.com/mover-code/golang-web3/abi"
 	"github.com/mover-code/golang-web3/contract"
 	"github.com/mover-code/golang-web3/jsonrpc"
+	"github.com/shopspring/decimal"
 )
 
 var (
@@ -57,6 +59,31 @@ func (t *Testdata) CallBasicInput(block ...web3.BlockNumber) (retval0 *big.Int,
 	return
 }
 
+// CallFixedOutput calls the callFixedOutput method in the solidity contract
+func (t *Testdata) CallFixedOutput(block ...web3.BlockNumber) (retval0 decimal.Decimal, retval1 abi.FunctionRef, err error) {
+	var out map[string]interface{}
+	var ok bool
+
+	out, err = t.c.Call("callFixedOutput", web3.EncodeBlock(block...))
+	if err != nil {
+		return
+	}
+
+	// decode outputs
+	retval0, ok = out["0"].(decimal.Decimal)
+	if !ok {
+		err = fmt.Errorf("failed to encode output at index 0")
+		return
+	}
+	retval1, ok = out["1"].(abi.FunctionRef)
+	if !ok {
+		err = fmt.Errorf("failed to encode output at index 1")
+		return
+	}
+	
+	return
+}
+
 // txns
 
 // TxnBasicInput sends a txnBasicInput transaction in the solidity contract
@@ -64,6 +91,11 @@ func (t *Testdata) TxnBasicInput(val1 web3.Address, val2 *big.Int) *contract.Txn
 	return t.c.Txn("txnBasicInput", val1, val2)
 }
 
+// TxnFixedInput sends a txnFixedInput transaction in the solidity contract
+func (t *Testdata) TxnFixedInput(price decimal.Decimal, callback abi.FunctionRef) *contract.Txn {
+	return t.c.Txn("txnFixedInput", price, callback)
+}
+
 // events
 
 func (t *Testdata) EventBasicEventSig() web3.Hash {
@@ -74,6 +74,42 @@ var abiTestdataStr = `[
         "stateMutability": "view",
         "type": "function"
     },
+    {
+        "constant": false,
+        "inputs": [
+            {
+                "name": "_price",
+                "type": "ufixed128x18"
+            },
+            {
+                "name": "_callback",
+                "type": "function"
+            }
+        ],
+        "name": "txnFixedInput",
+        "outputs": [],
+        "payable": false,
+        "stateMutability": "nonpayable",
+        "type": "function"
+    },
+    {
+        "constant": true,
+        "inputs": [],
+        "name": "callFixedOutput",
+        "outputs": [
+            {
+                "name": "",
+                "type": "fixed64x4"
+            },
+            {
+                "name": "",
+                "type": "function"
+            }
+        ],
+        "payable": false,
+        "stateMutability": "view",
+        "type": "function"
+    },
     {
         "anonymous": false,
         "inputs": [
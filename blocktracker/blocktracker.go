@@ -10,6 +10,7 @@ import (
 
 	web3 "github.com/mover-code/golang-web3"
 	"github.com/mover-code/golang-web3/jsonrpc"
+	"github.com/mover-code/golang-web3/jsonrpc/transport"
 )
 
 // BlockProvider are the eth1x methods required by the block tracker
@@ -38,6 +39,7 @@ type BlockTracker struct {
 type Config struct {
 	Tracker         BlockTrackerInterface
 	MaxBlockBacklog uint64
+	VerifyBlockHash bool
 }
 
 func DefaultConfig() *Config {
@@ -60,6 +62,14 @@ func WithTracker(b BlockTrackerInterface) ConfigOption {
 	}
 }
 
+// WithBlockHashVerification rejects the blocks whose header does not
+// match the hash returned by the provider
+func WithBlockHashVerification() ConfigOption {
+	return func(c *Config) {
+		c.VerifyBlockHash = true
+	}
+}
+
 func NewBlockTracker(provider BlockProvider, opts ...ConfigOption) *BlockTracker {
 	config := DefaultConfig()
 	for _, opt := range opts {
@@ -99,6 +109,9 @@ func (t *BlockTracker) Init() (err error) {
 		if err != nil {
 			return
 		}
+		if err = t.verifyBlock(block); err != nil {
+			return
+		}
 		if block.Number == 0 {
 			return
 		}
@@ -115,6 +128,9 @@ func (t *BlockTracker) Init() (err error) {
 			if err != nil {
 				return
 			}
+			if err = t.verifyBlock(block); err != nil {
+				return
+			}
 		}
 
 		if i != t.config.MaxBlockBacklog {
@@ -195,7 +211,21 @@ func (t *BlockTracker) blockAtIndex(hash web3.Hash) int {
 	return -1
 }
 
+func (t *BlockTracker) verifyBlock(block *web3.Block) error {
+	if !t.config.VerifyBlockHash {
+		return nil
+	}
+	if hash := block.ComputeHash(); hash != block.Hash {
+		return fmt.Errorf("block %d has hash %s but its header hashes to %s", block.Number, block.Hash, hash)
+	}
+	return nil
+}
+
 func (t *BlockTracker) handleReconcileImpl(block *web3.Block) ([]*web3.Block, int, error) {
+	if err := t.verifyBlock(block); err != nil {
+		return nil, -1, err
+	}
+
 	// The block already exists
 	if t.blockAtIndex(block.Hash) != -1 {
 		return nil, -1, nil
@@ -233,6 +263,9 @@ func (t *BlockTracker) handleReconcileImpl(block *web3.Block) ([]*web3.Block, in
 		if err != nil {
 			return nil, -1, fmt.Errorf("parent with hash %s not found", block.ParentHash)
 		}
+		if err := t.verifyBlock(parent); err != nil {
+			return nil, -1, err
+		}
 
 		added = append(added, parent)
 		if indx = t.blockAtIndex(parent.ParentHash); indx != -1 {
@@ -383,12 +416,23 @@ func NewSubscriptionBlockTracker(logger *log.Logger, client *jsonrpc.Client) (*S
 func (s *SubscriptionBlockTracker) Track(ctx context.Context, handle func(block *web3.Block) error) error {
 	data := make(chan []byte)
 	cancel, err := s.client.Subscribe("newHeads", func(b []byte) {
-		data <- b
+		select {
+		case data <- b:
+		case <-ctx.Done():
+		}
 	})
 	if err != nil {
 		return err
 	}
 
+	// the subscription is created again if the connection drops,
+	// query the last block on reconnection to backfill the gap.
+	var eventsCh <-chan *transport.ConnectionEvent
+	stopEvents := func() {}
+	if events, stop, err := s.client.ConnectionEvents(); err == nil {
+		eventsCh, stopEvents = events, stop
+	}
+
 	go func() {
 		for {
 			select {
@@ -400,8 +444,27 @@ func (s *SubscriptionBlockTracker) Track(ctx context.Context, handle func(block
 					handle(&block)
 				}
 
+			case evnt, ok := <-eventsCh:
+				if !ok {
+					// the transport is closed
+					eventsCh = nil
+					continue
+				}
+				if evnt.Type != transport.Reconnected {
+					s.logger.Printf("[WARN]: Tracker subscription disconnected: %v", evnt.Err)
+					continue
+				}
+				block, err := s.client.Eth().GetBlockByNumber(web3.Latest, false)
+				if err != nil {
+					s.logger.Printf("[ERR]: Tracker failed to get last block: %v", err)
+					continue
+				}
+				handle(block)
+
 			case <-ctx.Done():
 				cancel()
+				stopEvents()
+				return
 			}
 		}
 	}()
@@ -370,3 +370,29 @@ func TestBlockTracker_Events(t *testing.T) {
 		})
 	}
 }
+
+func TestBlockTracker_VerifyBlockHash(t *testing.T) {
+	m := &testutil.MockClient{}
+	tt := NewBlockTracker(m, WithBlockHashVerification())
+
+	block := &web3.Block{
+		Number:     1,
+		ParentHash: web3.Hash{0x1},
+		GasLimit:   30000000,
+	}
+	block.Hash = block.ComputeHash()
+
+	evnt, err := tt.HandleBlockEvent(block)
+	assert.NoError(t, err)
+	assert.Len(t, evnt.Added, 1)
+
+	// a block with a tampered header is rejected
+	fake := block.Copy()
+	fake.Number = 2
+	fake.ParentHash = block.Hash
+	fake.Hash = web3.Hash{0x2}
+
+	_, err = tt.HandleBlockEvent(fake)
+	assert.Error(t, err)
+	assert.Equal(t, tt.Len(), 1)
+}
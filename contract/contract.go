@@ -1,13 +1,21 @@
 package contract
 
 import (
+	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
+	"strings"
+	"time"
 
 	"github.com/mover-code/golang-web3/jsonrpc"
+	"github.com/mover-code/golang-web3/jsonrpc/codec"
 
 	"github.com/mover-code/golang-web3/abi"
+	"github.com/mover-code/golang-web3/feeoracle"
+	"github.com/mover-code/golang-web3/nonce"
+	"github.com/mover-code/golang-web3/wallet"
 
 	web3 "github.com/mover-code/golang-web3"
 )
@@ -18,6 +26,14 @@ type Contract struct {
 	from     *web3.Address
 	abi      *abi.ABI
 	provider *jsonrpc.Client
+
+	key     web3.Key
+	signer  wallet.Signer
+	chainID uint64
+	nonces  *nonce.Manager
+
+	feeOracle   *feeoracle.Oracle
+	feeStrategy feeoracle.Strategy
 }
 
 // DeployContract deploys a contract
@@ -25,19 +41,29 @@ func DeployContract(provider *jsonrpc.Client, from web3.Address, abi *abi.ABI, b
 	return &Txn{
 		from:     from,
 		provider: provider,
+		abi:      abi,
 		method:   abi.Constructor,
 		args:     args,
 		bin:      bin,
+
+		feeStrategy: feeoracle.Standard,
+		waitTimeout: defaultWaitTimeout,
 	}
 }
 
 // NewContract creates a new contract instance
 func NewContract(addr web3.Address, abi *abi.ABI, provider *jsonrpc.Client) *Contract {
-	return &Contract{
-		addr:     addr,
-		abi:      abi,
-		provider: provider,
+	c := &Contract{
+		addr:        addr,
+		abi:         abi,
+		provider:    provider,
+		feeStrategy: feeoracle.Standard,
+	}
+	if provider != nil {
+		// the transactions of the contract share the default fee oracle
+		c.feeOracle = feeoracle.NewOracle(provider.Eth())
 	}
+	return c
 }
 
 // ABI returns the abi of the contract
@@ -55,16 +81,52 @@ func (c *Contract) SetFrom(addr web3.Address) {
 	c.from = &addr
 }
 
+// SetKey signs the transactions of the contract locally with the key
+// and an EIP-155 signer for the chain id. If the chain id is zero, it is
+// queried from the provider. The address of the key is the origin of the calls.
+func (c *Contract) SetKey(key web3.Key, chainID uint64) {
+	c.SetFrom(key.Address())
+	c.key = key
+	c.chainID = chainID
+}
+
+// SetSigner signs the transactions of the contract locally with
+// the key and a custom signer
+func (c *Contract) SetSigner(key web3.Key, signer wallet.Signer) {
+	c.SetFrom(key.Address())
+	c.key = key
+	c.signer = signer
+}
+
+// SetNonceManager sets the nonce manager that hands out
+// the nonces of the transactions of the contract
+func (c *Contract) SetNonceManager(m *nonce.Manager) {
+	c.nonces = m
+}
+
+// SetFeeOracle sets the fee oracle and the strategy used for the
+// fees of the transactions of the contract without a gas price
+func (c *Contract) SetFeeOracle(o *feeoracle.Oracle, strategy feeoracle.Strategy) {
+	c.feeOracle = o
+	c.feeStrategy = strategy
+}
+
 // EstimateGas estimates the gas for a contract call
 func (c *Contract) EstimateGas(method string, args ...interface{}) (uint64, error) {
-	return c.Txn(method, args).EstimateGas()
+	return c.Txn(method, args...).EstimateGas()
 }
 
-// Call calls a method in the contract
+// Call calls a method in the contract. The method is either the name or,
+// for overloaded methods, the signature (i.e. 'transfer(address,uint256)').
 func (c *Contract) Call(method string, block web3.BlockNumber, args ...interface{}) (map[string]interface{}, error) {
-	m, ok := c.abi.Methods[method]
-	if !ok {
-		return nil, fmt.Errorf("method %s not found", method)
+	return c.CallContext(context.Background(), method, block, args...)
+}
+
+// CallContext calls a method in the contract and returns once the context is done
+func (c *Contract) CallContext(ctx context.Context, method string, block web3.BlockNumber, args ...interface{}) (map[string]interface{}, error) {
+	m, err := c.abi.FindMethod(method, args...)
+	if err != nil {
+		return nil, err
 	}
 
 	// Encode input
@@ -83,9 +145,9 @@ func (c *Contract) Call(method string, block web3.BlockNumber, args ...interface
 		msg.From = *c.from
 	}
 
-	rawStr, err := c.provider.Eth().Call(msg, block)
+	rawStr, err := c.provider.Eth().WithContext(ctx).Call(msg, block)
 	if err != nil {
-		return nil, err
+		return nil, decodeRevert(c.abi, err)
 	}
 
 	// Decode output
@@ -96,34 +158,34 @@ func (c *Contract) Call(method string, block web3.BlockNumber, args ...interface
 	if len(raw) == 0 {
 		return nil, fmt.Errorf("empty response")
 	}
-	respInterface, err := abi.Decode(m.Outputs, raw)
-	if err != nil {
-		return nil, err
-	}
-
-	resp := respInterface.(map[string]interface{})
-	return resp, nil
+	return m.DecodeOutput(raw)
 }
 
-// Txn creates a new transaction object
+// Txn creates a new transaction object. As in Call, the method is
+// either the name or the signature of the method. If the method is not
+// found, the error is returned when the transaction is used.
 func (c *Contract) Txn(method string, args ...interface{}) *Txn {
-	m, ok := c.abi.Methods[method]
-	if !ok {
-		// TODO, return error
-		panic(fmt.Errorf("method %s not found", method))
-	}
-
-	// Encode input
-	data, _ := abi.Encode(args, m.Inputs)
-	data = append(m.ID(), data...)
-	return &Txn{
-		from:     *c.from,
+	txn := &Txn{
 		addr:     &c.addr,
 		provider: c.provider,
-		method:   m,
+		abi:      c.abi,
 		args:     args,
-		data:     data,
+		key:      c.key,
+		signer:   c.signer,
+		chainID:  c.chainID,
+		nonces:   c.nonces,
+
+		feeOracle:   c.feeOracle,
+		feeStrategy: c.feeStrategy,
+		waitTimeout: defaultWaitTimeout,
 	}
+	if c.from != nil {
+		txn.from = *c.from
+	}
+
+	// the error is returned when the transaction is used
+	txn.method, txn.err = c.abi.FindMethod(method, args...)
+	return txn
 }
 
 // Txn is a transaction object
@@ -131,6 +193,7 @@ type Txn struct {
 	from     web3.Address
 	addr     *web3.Address
 	provider *jsonrpc.Client
+	abi      *abi.ABI
 	method   *abi.Method
 	args     []interface{}
 	data     []byte
@@ -138,44 +201,225 @@ type Txn struct {
 	gasLimit uint64
 	gasPrice uint64
 	value    *big.Int
+	nonce    *uint64
 	hash     web3.Hash
+	txn      *web3.Transaction
 	receipt  *web3.Receipt
+
+	// err is the error of the method of the transaction
+	err error
+
+	// eip-1559 fees, the gas price is not used if they are set
+	maxFeePerGas         *big.Int
+	maxPriorityFeePerGas *big.Int
+
+	// feeOracle suggests the fees if there is no gas price,
+	// by default it is an oracle of the provider
+	feeOracle   *feeoracle.Oracle
+	feeStrategy feeoracle.Strategy
+
+	accessList web3.AccessList
+
+	// local signing, the transaction is sent with eth_sendTransaction
+	// if there is no key
+	key     web3.Key
+	signer  wallet.Signer
+	chainID uint64
+
+	// nonces is the shared source of nonces of the sender, managedNonce
+	// is set if the nonce of the transaction comes from it
+	nonces       *nonce.Manager
+	managedNonce bool
+
+	ctx           context.Context
+	confirmations uint64
+	pollInterval  time.Duration
+	waitTimeout   time.Duration
+}
+
+const (
+	defaultPollInterval = 1 * time.Second
+	defaultWaitTimeout  = 5 * time.Minute
+)
+
+// ErrWaitTimeout happens when the transaction is not mined
+// before the wait timeout
+var ErrWaitTimeout = fmt.Errorf("timeout waiting for the transaction receipt")
+
+// WithContext sets the context used for the requests of the transaction
+func (t *Txn) WithContext(ctx context.Context) *Txn {
+	t.ctx = ctx
+	return t
+}
+
+func (t *Txn) context() context.Context {
+	if t.ctx == nil {
+		return context.Background()
+	}
+	return t.ctx
+}
+
+func (t *Txn) eth() *jsonrpc.Eth {
+	return t.provider.Eth().WithContext(t.context())
+}
+
+// MarshalTrans returns the transaction with the fees, the gas limit
+// and the nonce filled, ready to be signed. If the nonce is not set, it is
+// the pending nonce of the sender. The nonce manager only hands out nonces
+// to the transactions sent with Do.
+func (t *Txn) MarshalTrans() (*web3.Transaction, error) {
+	if err := t.populate(); err != nil {
+		return nil, err
+	}
+	txn := t.transaction()
+	if t.nonce == nil {
+		nonce, err := t.eth().GetNonce(t.from, web3.Pending)
+		if err != nil {
+			return nil, err
+		}
+		txn.Nonce = nonce
+	}
+	return txn, nil
+}
+
+// acquireNonce sets the nonce of the transaction from the
+// nonce manager if there is one and the nonce is not set
+func (t *Txn) acquireNonce() error {
+	if t.nonce != nil || t.nonces == nil {
+		return nil
+	}
+	nonce, err := t.nonces.Next(t.from)
+	if err != nil {
+		return err
+	}
+	t.nonce = &nonce
+	t.managedNonce = true
+	return nil
 }
 
-func (t *Txn) MarshalTrans() *web3.Transaction {
+// releaseNonce hands back the nonce to the nonce manager after the
+// submission of the transaction failed with err and returns err
+func (t *Txn) releaseNonce(err error) error {
+	if !t.managedNonce {
+		return err
+	}
+	nonce := *t.nonce
+	t.nonce = nil
+	t.managedNonce = false
+
+	if releaseErr := t.nonces.Failed(t.from, nonce, err); releaseErr != nil {
+		return fmt.Errorf("%w (failed to release the nonce: %v)", err, releaseErr)
+	}
+	return err
+}
+
+// reclaimNonce hands back the nonce to the nonce manager
+// when the transaction could not be sent
+func (t *Txn) reclaimNonce() {
+	if !t.managedNonce {
+		return
+	}
+	t.nonces.Reclaim(t.from, *t.nonce)
+	t.nonce = nil
+	t.managedNonce = false
+}
+
+// populate validates the transaction and fills the fees and the gas limit
+func (t *Txn) populate() error {
 	err := t.Validate()
 	if err != nil {
-		return nil
+		return err
 	}
 
-	// estimate gas price
-	if t.gasPrice == 0 {
-		t.gasPrice, err = t.provider.Eth().GasPrice()
-		if err != nil {
-			return nil
+	// suggest fees
+	if t.gasPrice == 0 && t.maxFeePerGas == nil {
+		if err := t.suggestFees(); err != nil {
+			return err
 		}
 	}
 	// estimate gas limit
 	if t.gasLimit == 0 {
 		t.gasLimit, err = t.estimateGas()
 		if err != nil {
-			return nil
+			return err
 		}
 	}
+	return nil
+}
 
-	block, _ := t.provider.Eth().BlockNumber()
-	nonce, _ := t.provider.Eth().GetNonce(t.from, web3.BlockNumber(int64(block)))
-	return &web3.Transaction{
-		From:        t.from,
-		To:          t.addr,
-		Hash:        t.hash,
-		Gas:         t.gasLimit,
-		GasPrice:    t.gasPrice,
-		Value:       t.value,
-		Input:       t.data,
-		BlockNumber: block,
-		Nonce:       nonce,
+// suggestFees sets the fees of the transaction from the fee oracle, which
+// are the EIP-1559 fees or the gas price if the chain does not support them
+func (t *Txn) suggestFees() error {
+	oracle := t.feeOracle
+	if oracle == nil {
+		oracle = feeoracle.NewOracle(t.eth())
+	}
+	fees, err := oracle.Fees(t.feeStrategy)
+	if err != nil {
+		return err
+	}
+	if fees.IsLegacy() {
+		t.gasPrice = fees.GasPrice
+	} else {
+		t.maxFeePerGas = fees.MaxFeePerGas
+		t.maxPriorityFeePerGas = fees.MaxPriorityFeePerGas
 	}
+	return nil
+}
+
+func (t *Txn) transaction() *web3.Transaction {
+	txn := &web3.Transaction{
+		From:     t.from,
+		To:       t.addr,
+		Input:    t.data,
+		GasPrice: t.gasPrice,
+		Gas:      t.gasLimit,
+		Value:    t.value,
+	}
+	if t.nonce != nil {
+		txn.Nonce = *t.nonce
+	}
+	if len(t.accessList) != 0 {
+		txn.Type = web3.TransactionAccessList
+		txn.AccessList = t.accessList
+	}
+	if t.maxFeePerGas != nil {
+		txn.Type = web3.TransactionDynamicFee
+		txn.GasPrice = 0
+		txn.MaxFeePerGas = t.maxFeePerGas
+		txn.MaxPriorityFeePerGas = t.maxPriorityFeePerGas
+		if txn.MaxPriorityFeePerGas == nil {
+			txn.MaxPriorityFeePerGas = new(big.Int)
+		}
+	}
+	return txn
+}
+
+// Sign returns the transaction signed with the key of the transaction
+func (t *Txn) Sign() (*web3.Transaction, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	if t.key == nil {
+		return nil, fmt.Errorf("no key to sign the transaction")
+	}
+	txn, err := t.MarshalTrans()
+	if err != nil {
+		return nil, err
+	}
+	signer := t.signer
+	if signer == nil {
+		chainID := t.chainID
+		if chainID == 0 {
+			num, err := t.eth().ChainID()
+			if err != nil {
+				return nil, err
+			}
+			chainID = num.Uint64()
+		}
+		signer = wallet.NewEIP155Signer(chainID)
+	}
+	return signer.SignTx(txn, t.key)
 }
 
 func (t *Txn) isContractDeployment() bool {
@@ -203,17 +447,47 @@ func (t *Txn) EstimateGas() (uint64, error) {
 }
 
 func (t *Txn) estimateGas() (uint64, error) {
+	var gas uint64
+	var err error
 	if t.isContractDeployment() {
-		return t.provider.Eth().EstimateGasContract(t.data)
+		gas, err = t.eth().EstimateGasContract(t.data)
+	} else {
+		gas, err = t.eth().EstimateGas(t.callMsg())
+	}
+	if err != nil {
+		return 0, decodeRevert(t.abi, err)
 	}
+	return gas, nil
+}
 
-	msg := &web3.CallMsg{
-		From:  t.from,
-		To:    t.addr,
-		Data:  t.data,
-		Value: t.value,
+func (t *Txn) callMsg() *web3.CallMsg {
+	return &web3.CallMsg{
+		From:       t.from,
+		To:         t.addr,
+		Data:       t.data,
+		Value:      t.value,
+		AccessList: t.accessList,
 	}
-	return t.provider.Eth().EstimateGas(msg)
+}
+
+// SetAccessList sets the EIP-2930 access list of the transaction
+func (t *Txn) SetAccessList(accessList web3.AccessList) *Txn {
+	t.accessList = accessList
+	return t
+}
+
+// CreateAccessList generates the access list of the transaction with
+// eth_createAccessList and attaches it to the transaction
+func (t *Txn) CreateAccessList() (web3.AccessList, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	accessList, _, err := t.eth().CreateAccessList(t.callMsg(), web3.Latest)
+	if err != nil {
+		return nil, err
+	}
+	t.accessList = accessList
+	return accessList, nil
 }
 
 // DoAndWait is a blocking query that combines
@@ -228,48 +502,48 @@ func (t *Txn) DoAndWait() error {
 	return nil
 }
 
-// Do sends the transaction to the network
+// Do sends the transaction to the network. If the transaction has
+// a key, it is signed locally and sent with eth_sendRawTransaction.
 func (t *Txn) Do() error {
-	err := t.Validate()
-	if err != nil {
+	if err := t.populate(); err != nil {
+		return err
+	}
+	if err := t.acquireNonce(); err != nil {
 		return err
 	}
 
-	// estimate gas price
-	if t.gasPrice == 0 {
-		t.gasPrice, err = t.provider.Eth().GasPrice()
+	if t.key != nil {
+		txn, err := t.Sign()
 		if err != nil {
+			t.reclaimNonce()
 			return err
 		}
-	}
-	// estimate gas limit
-	if t.gasLimit == 0 {
-		t.gasLimit, err = t.estimateGas()
+		t.hash, err = t.eth().SendRawTransaction(txn.MarshalRLP())
 		if err != nil {
-			return err
+			return decodeRevert(t.abi, t.releaseNonce(err))
 		}
+		txn.Hash = t.hash
+		t.txn = txn
+		return nil
 	}
 
 	// send transaction
-	txn := &web3.Transaction{
-		From:     t.from,
-		Input:    t.data,
-		GasPrice: t.gasPrice,
-		Gas:      t.gasLimit,
-		Value:    t.value,
-	}
-	if t.addr != nil {
-		txn.To = t.addr
-	}
-	t.hash, err = t.provider.Eth().SendTransaction(txn)
+	txn := t.transaction()
+	hash, err := t.eth().SendTransaction(txn)
 	if err != nil {
-		return err
+		return decodeRevert(t.abi, t.releaseNonce(err))
 	}
+	txn.Hash = hash
+	t.hash = hash
+	t.txn = txn
 	return nil
 }
 
 // Validate validates the arguments of the transaction
 func (t *Txn) Validate() error {
+	if t.err != nil {
+		return t.err
+	}
 	if t.data != nil {
 		// Already validated
 		return nil
@@ -297,31 +571,143 @@ func (t *Txn) SetGasPrice(gasPrice uint64) *Txn {
 	return t
 }
 
+// SetMaxFees sets the EIP-1559 fees of the transaction, which
+// is sent as a dynamic fee transaction
+func (t *Txn) SetMaxFees(maxFeePerGas, maxPriorityFeePerGas *big.Int) *Txn {
+	t.maxFeePerGas = maxFeePerGas
+	t.maxPriorityFeePerGas = maxPriorityFeePerGas
+	return t
+}
+
+// SetFeeOracle sets the fee oracle and the strategy used
+// for the fees if the transaction has no gas price
+func (t *Txn) SetFeeOracle(o *feeoracle.Oracle, strategy feeoracle.Strategy) *Txn {
+	t.feeOracle = o
+	t.feeStrategy = strategy
+	return t
+}
+
+// SetNonce sets the nonce of the transaction instead of the
+// pending nonce of the sender
+func (t *Txn) SetNonce(nonce uint64) *Txn {
+	t.nonce = &nonce
+	return t
+}
+
+// SetNonceManager sets the nonce manager that hands out the nonce
+// of the transaction if it is not set explicitly
+func (t *Txn) SetNonceManager(m *nonce.Manager) *Txn {
+	t.nonces = m
+	return t
+}
+
+// SetKey signs the transaction locally with the key and an EIP-155
+// signer for the chain id. If the chain id is zero, it is queried
+// from the provider.
+func (t *Txn) SetKey(key web3.Key, chainID uint64) *Txn {
+	t.from = key.Address()
+	t.key = key
+	t.chainID = chainID
+	return t
+}
+
+// SetSigner signs the transaction locally with the key and a custom signer
+func (t *Txn) SetSigner(key web3.Key, signer wallet.Signer) *Txn {
+	t.from = key.Address()
+	t.key = key
+	t.signer = signer
+	return t
+}
+
+// Hash returns the hash of the transaction after Do
+func (t *Txn) Hash() web3.Hash {
+	return t.hash
+}
+
+// Transaction returns the transaction sent in Do, which
+// is signed if the transaction has a key
+func (t *Txn) Transaction() *web3.Transaction {
+	return t.txn
+}
+
 // SetGasLimit sets the gas limit of the transaction
 func (t *Txn) SetGasLimit(gasLimit uint64) *Txn {
 	t.gasLimit = gasLimit
 	return t
 }
 
-// Wait waits till the transaction is mined
+// SetConfirmations sets the number of blocks that Wait waits on top
+// of the block that includes the transaction
+func (t *Txn) SetConfirmations(confirmations uint64) *Txn {
+	t.confirmations = confirmations
+	return t
+}
+
+// SetPollInterval sets the interval to query the receipt in Wait
+func (t *Txn) SetPollInterval(interval time.Duration) *Txn {
+	t.pollInterval = interval
+	return t
+}
+
+// SetWaitTimeout sets the maximum time Wait waits for the transaction.
+// A zero value disables the timeout.
+func (t *Txn) SetWaitTimeout(timeout time.Duration) *Txn {
+	t.waitTimeout = timeout
+	return t
+}
+
+// Wait waits till the transaction is mined and has the
+// required number of confirmations
 func (t *Txn) Wait() error {
 	if (t.hash == web3.Hash{}) {
 		panic("transaction not executed")
 	}
 
-	var err error
+	ctx := t.context()
+	if t.waitTimeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.waitTimeout)
+		defer cancel()
+	}
+	pollInterval := t.pollInterval
+	if pollInterval == 0 {
+		pollInterval = defaultPollInterval
+	}
+
+	eth := t.provider.Eth().WithContext(ctx)
 	for {
-		t.receipt, err = t.provider.Eth().GetTransactionReceipt(t.hash)
-		if err != nil {
-			if err.Error() != "not found" {
-				return err
+		// the receipt is nil while the transaction is pending
+		receipt, err := eth.GetTransactionReceipt(t.hash)
+		if err != nil && ctx.Err() == nil && !isNotFound(err) {
+			return err
+		}
+		if receipt != nil {
+			if t.confirmations == 0 {
+				t.receipt = receipt
+				return nil
+			}
+			num, err := eth.BlockNumber()
+			if err != nil {
+				if ctx.Err() == nil {
+					return err
+				}
+			} else if num >= receipt.BlockNumber+t.confirmations {
+				// the receipt is queried on each iteration in case the
+				// transaction is removed from the chain during a reorg
+				t.receipt = receipt
+				return nil
 			}
 		}
-		if t.receipt != nil {
-			break
+
+		select {
+		case <-time.After(pollInterval):
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded && t.context().Err() == nil {
+				return ErrWaitTimeout
+			}
+			return ctx.Err()
 		}
 	}
-	return nil
 }
 
 // Receipt returns the receipt of the transaction after wait
@@ -352,3 +738,31 @@ func (c *Contract) Event(name string) (*Event, bool) {
 	}
 	return &Event{event}, true
 }
+
+// isNotFound returns whether the error is the reply of the nodes that
+// return an error instead of null for the receipts of pending transactions
+func isNotFound(err error) bool {
+	var obj *codec.ErrorObject
+	if !errors.As(err, &obj) {
+		return false
+	}
+	return strings.Contains(strings.ToLower(obj.Message), "not found")
+}
+
+// decodeRevert returns an abi.RevertError if the jsonrpc error includes
+// the revert data of the execution, otherwise it returns the same error
+func decodeRevert(a *abi.ABI, err error) error {
+	var obj *codec.ErrorObject
+	if !errors.As(err, &obj) {
+		return err
+	}
+	data, ok := obj.RevertData()
+	if !ok {
+		return err
+	}
+	revertErr, decodeErr := abi.DecodeRevert(a, data)
+	if decodeErr != nil {
+		return err
+	}
+	return revertErr
+}
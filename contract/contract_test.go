@@ -1,15 +1,26 @@
 package contract
 
 import (
+	"context"
 	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/mover-code/golang-web3/jsonrpc"
 
 	"github.com/mover-code/golang-web3/testutil"
 
 	"github.com/mover-code/golang-web3/abi"
+	"github.com/mover-code/golang-web3/feeoracle"
+	"github.com/mover-code/golang-web3/nonce"
+	"github.com/mover-code/golang-web3/wallet"
 
 	web3 "github.com/mover-code/golang-web3"
 	"github.com/stretchr/testify/assert"
@@ -109,3 +120,415 @@ func TestDeployContract(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, resp["0"], big.NewInt(1000))
 }
+
+func TestContractCallRevert(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// revert with the custom error Unauthorized(address)
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted","data":"0x8e4a23d60000000000000000000000000100000000000000000000000000000000000000"}}`))
+	}))
+	defer srv.Close()
+
+	provider, err := jsonrpc.NewClient(srv.URL)
+	assert.NoError(t, err)
+
+	abi0, err := abi.NewABI(`[
+		{"type": "function", "name": "set", "inputs": [], "outputs": []},
+		{"type": "error", "name": "Unauthorized", "inputs": [{"name": "caller", "type": "address"}]}
+	]`)
+	assert.NoError(t, err)
+
+	c := NewContract(addr0B, abi0, provider)
+
+	_, err = c.Call("set", web3.Latest)
+	assert.Error(t, err)
+
+	revertErr, ok := err.(*abi.RevertError)
+	assert.True(t, ok)
+	assert.Equal(t, revertErr.Name, "Unauthorized")
+	assert.Equal(t, revertErr.Args["caller"], web3.Address{0x1})
+}
+
+func TestContractTxnLocalSigning(t *testing.T) {
+	key, err := wallet.GenerateKey()
+	assert.NoError(t, err)
+
+	var sent *web3.Transaction
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string
+			Params []interface{}
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var result string
+		switch req.Method {
+		case "eth_chainId":
+			result = "0x539"
+		case "eth_feeHistory":
+			assert.Equal(t, req.Params, []interface{}{"0x14", "latest", []interface{}{float64(50)}})
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"oldestBlock":"0x1","baseFeePerGas":["0x64","0x64","0x64"],"gasUsedRatio":[0.5,0.5],"reward":[["0x2"],["0x4"]]}}`))
+			return
+		case "eth_estimateGas":
+			result = "0x5208"
+		case "eth_getTransactionCount":
+			assert.Equal(t, req.Params, []interface{}{key.Address().String(), "pending"})
+			result = "0x5"
+		case "eth_sendRawTransaction":
+			raw, err := hex.DecodeString(req.Params[0].(string)[2:])
+			assert.NoError(t, err)
+			sent, err = wallet.DecodeTransaction(wallet.NewEIP155Signer(1337), raw)
+			assert.NoError(t, err)
+			result = web3.Hash{0x1}.String()
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method not found"}}`))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"` + result + `"}`))
+	}))
+	defer srv.Close()
+
+	provider, err := jsonrpc.NewClient(srv.URL)
+	assert.NoError(t, err)
+
+	abi0, err := abi.NewABI(`[
+		{"type": "function", "name": "set", "inputs": [{"name": "val", "type": "uint256"}], "outputs": []}
+	]`)
+	assert.NoError(t, err)
+
+	c := NewContract(addr0B, abi0, provider)
+	c.SetKey(key, 0)
+
+	txn := c.Txn("set", big.NewInt(1))
+	assert.NoError(t, txn.Do())
+	assert.Equal(t, txn.Hash(), web3.Hash{0x1})
+
+	assert.Equal(t, sent.From, key.Address())
+	assert.Equal(t, sent.Nonce, uint64(5))
+	assert.Equal(t, sent.Gas, uint64(21000))
+
+	// the fees are suggested by the standard strategy of the fee oracle
+	assert.Equal(t, sent.Type, web3.TransactionDynamicFee)
+	assert.Equal(t, sent.MaxPriorityFeePerGas, big.NewInt(4))
+	assert.Equal(t, sent.MaxFeePerGas, big.NewInt(129))
+	assert.Equal(t, *sent.To, addr0B)
+
+	// eip-1559 transaction with explicit fees and nonce
+	txn = c.Txn("set", big.NewInt(2)).
+		SetNonce(10).
+		SetMaxFees(big.NewInt(100), big.NewInt(2))
+	assert.NoError(t, txn.Do())
+
+	assert.Equal(t, sent.From, key.Address())
+	assert.Equal(t, sent.Type, web3.TransactionDynamicFee)
+	assert.Equal(t, sent.Nonce, uint64(10))
+	assert.Equal(t, sent.MaxFeePerGas, big.NewInt(100))
+	assert.Equal(t, sent.MaxPriorityFeePerGas, big.NewInt(2))
+
+	// legacy transaction with an explicit gas price
+	txn = c.Txn("set", big.NewInt(2)).SetGasPrice(1000000000)
+	assert.NoError(t, txn.Do())
+
+	assert.Equal(t, sent.Type, web3.TransactionLegacy)
+	assert.Equal(t, sent.GasPrice, uint64(1000000000))
+
+	// transactions that share the nonce manager get sequential nonces
+	c.SetNonceManager(nonce.NewManager(provider.Eth()))
+	for i := uint64(0); i < 3; i++ {
+		assert.NoError(t, c.Txn("set", big.NewInt(3)).Do())
+		assert.Equal(t, sent.Nonce, 5+i)
+	}
+
+	// any key that signs hashes, like a remote signer
+	remote := &remoteKey{key: key}
+	c.SetSigner(remote, wallet.NewEIP155Signer(1337))
+	assert.NoError(t, c.Txn("set", big.NewInt(4)).Do())
+	assert.Equal(t, remote.signed, 1)
+	assert.Equal(t, sent.From, key.Address())
+}
+
+// remoteKey is a key that signs the hashes with another key
+type remoteKey struct {
+	key    *wallet.Key
+	signed int
+}
+
+func (r *remoteKey) Address() web3.Address {
+	return r.key.Address()
+}
+
+func (r *remoteKey) Sign(hash []byte) ([]byte, error) {
+	r.signed++
+	return r.key.Sign(hash)
+}
+
+func TestContractFeeOracle(t *testing.T) {
+	provider, err := jsonrpc.NewClient("http://localhost:8545")
+	assert.NoError(t, err)
+
+	abi0, err := abi.NewABI(`[
+		{"type": "function", "name": "set", "inputs": [{"name": "val", "type": "uint256"}], "outputs": []}
+	]`)
+	assert.NoError(t, err)
+
+	// the transactions share the default fee oracle of the contract
+	c := NewContract(addr0B, abi0, provider)
+	assert.NotNil(t, c.feeOracle)
+	assert.Same(t, c.Txn("set", big.NewInt(1)).feeOracle, c.feeOracle)
+	assert.Same(t, c.Txn("set", big.NewInt(2)).feeOracle, c.feeOracle)
+
+	oracle := feeoracle.NewOracle(provider.Eth())
+	c.SetFeeOracle(oracle, feeoracle.Fast)
+
+	txn := c.Txn("set", big.NewInt(1))
+	assert.Same(t, txn.feeOracle, oracle)
+	assert.Equal(t, txn.feeStrategy, feeoracle.Fast)
+}
+
+func TestContractTxnMethodNotFound(t *testing.T) {
+	provider, err := jsonrpc.NewClient("http://localhost:8545")
+	assert.NoError(t, err)
+
+	abi0, err := abi.NewABI(`[
+		{"type": "function", "name": "set", "inputs": [{"name": "val", "type": "uint256"}], "outputs": []}
+	]`)
+	assert.NoError(t, err)
+
+	key, err := wallet.GenerateKey()
+	assert.NoError(t, err)
+
+	c := NewContract(addr0B, abi0, provider)
+	c.SetKey(key, 1)
+
+	// the error is returned before any request is sent
+	txn := c.Txn("get")
+	assert.EqualError(t, txn.Do(), "method get not found")
+
+	_, err = txn.EstimateGas()
+	assert.EqualError(t, err, "method get not found")
+
+	_, err = txn.MarshalTrans()
+	assert.EqualError(t, err, "method get not found")
+
+	_, err = txn.Sign()
+	assert.EqualError(t, err, "method get not found")
+
+	// the arguments that do not match the inputs fail the same way
+	assert.Error(t, c.Txn("set", "a", "b").Do())
+}
+
+func TestContractTxnReleaseNonce(t *testing.T) {
+	key, err := wallet.GenerateKey()
+	assert.NoError(t, err)
+
+	// sendResp is the response of eth_sendRawTransaction and
+	// nonceResp the one of eth_getTransactionCount
+	var sendResp, nonceResp string
+	var sent *web3.Transaction
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string
+			Params []interface{}
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var resp string
+		switch req.Method {
+		case "eth_estimateGas":
+			resp = `"result":"0x5208"`
+		case "eth_getTransactionCount":
+			resp = nonceResp
+		case "eth_sendRawTransaction":
+			raw, err := hex.DecodeString(req.Params[0].(string)[2:])
+			assert.NoError(t, err)
+			sent, err = wallet.DecodeTransaction(wallet.NewEIP155Signer(1337), raw)
+			assert.NoError(t, err)
+			resp = sendResp
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+			resp = `"error":{"code":-32601,"message":"method not found"}`
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,` + resp + `}`))
+	}))
+	defer srv.Close()
+
+	provider, err := jsonrpc.NewClient(srv.URL)
+	assert.NoError(t, err)
+
+	abi0, err := abi.NewABI(`[
+		{"type": "function", "name": "set", "inputs": [{"name": "val", "type": "uint256"}], "outputs": []}
+	]`)
+	assert.NoError(t, err)
+
+	c := NewContract(addr0B, abi0, provider)
+	c.SetKey(key, 1337)
+	c.SetNonceManager(nonce.NewManager(provider.Eth()))
+
+	do := func() error {
+		return c.Txn("set", big.NewInt(1)).SetGasPrice(1).Do()
+	}
+
+	// the transactions that are not sent do not take nonces
+	nonceResp = `"result":"0x5"`
+	for i := 0; i < 2; i++ {
+		txn, err := c.Txn("set", big.NewInt(1)).SetGasPrice(1).MarshalTrans()
+		assert.NoError(t, err)
+		assert.Equal(t, txn.Nonce, uint64(5))
+
+		txn, err = c.Txn("set", big.NewInt(1)).SetGasPrice(1).Sign()
+		assert.NoError(t, err)
+		assert.Equal(t, txn.Nonce, uint64(5))
+	}
+
+	// the nonce of a rejected transaction is reused
+	sendResp = `"error":{"code":-32000,"message":"insufficient funds for gas * price + value"}`
+	assert.Error(t, do())
+	assert.Equal(t, sent.Nonce, uint64(5))
+
+	sendResp = `"result":"` + web3.Hash{0x1}.String() + `"`
+	assert.NoError(t, do())
+	assert.Equal(t, sent.Nonce, uint64(5))
+
+	// the errors to resync the nonce are returned with the send error
+	sendResp = `"error":{"code":-32000,"message":"nonce too low"}`
+	nonceResp = `"error":{"code":-32000,"message":"header not found"}`
+	err = do()
+	assert.True(t, nonce.IsNonceTooLow(err))
+	assert.Contains(t, err.Error(), "header not found")
+}
+
+// receiptResult is the result of eth_getTransactionReceipt
+// for a transaction included in the block
+func receiptResult(block uint64) string {
+	return fmt.Sprintf(`"result":{
+		"status": "0x1",
+		"from": "%s",
+		"transactionHash": "%s",
+		"blockHash": "%s",
+		"transactionIndex": "0x0",
+		"blockNumber": "0x%x",
+		"gasUsed": "0x5208",
+		"cumulativeGasUsed": "0x5208",
+		"logsBloom": "0x%s",
+		"logs": []
+	}`, addr0, web3.Hash{0x1}, web3.Hash{byte(block)}, block, strings.Repeat("00", 256))
+}
+
+// newWaitServer is a node that replies to the receipt queries with the
+// responses in order, repeating the last one, and whose head advances
+// one block on each query of the block number
+func newWaitServer(t *testing.T, head uint64, responses ...string) (*httptest.Server, *int) {
+	var lock sync.Mutex
+	queries := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		var resp string
+		switch req.Method {
+		case "eth_getTransactionReceipt":
+			indx := queries
+			if indx >= len(responses) {
+				indx = len(responses) - 1
+			}
+			resp = responses[indx]
+			queries++
+		case "eth_blockNumber":
+			resp = fmt.Sprintf(`"result":"0x%x"`, head)
+			head++
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+			resp = `"error":{"code":-32601,"message":"method not found"}`
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,` + resp + `}`))
+	}))
+	return srv, &queries
+}
+
+func newWaitTxn(t *testing.T, url string) *Txn {
+	provider, err := jsonrpc.NewClient(url)
+	assert.NoError(t, err)
+
+	abi0, err := abi.NewABI(`[
+		{"type": "function", "name": "set", "inputs": [], "outputs": []}
+	]`)
+	assert.NoError(t, err)
+
+	txn := NewContract(addr0B, abi0, provider).Txn("set")
+	txn.hash = web3.Hash{0x1}
+	return txn.SetPollInterval(10 * time.Millisecond)
+}
+
+func TestTxnWait_Pending(t *testing.T) {
+	srv, queries := newWaitServer(t, 0, `"result":null`, `"result":null`, receiptResult(5))
+	defer srv.Close()
+
+	txn := newWaitTxn(t, srv.URL)
+	assert.NoError(t, txn.Wait())
+
+	// the receipt is queried until the transaction is mined
+	assert.Equal(t, *queries, 3)
+	assert.Equal(t, txn.Receipt().BlockNumber, uint64(5))
+	assert.Equal(t, txn.Receipt().TransactionHash, web3.Hash{0x1})
+}
+
+func TestTxnWait_Confirmations(t *testing.T) {
+	// the transaction is mined in block 10, removed by a reorg
+	// and mined again in block 12
+	srv, queries := newWaitServer(t, 11, receiptResult(10), `"result":null`, receiptResult(12))
+	defer srv.Close()
+
+	txn := newWaitTxn(t, srv.URL).SetConfirmations(2)
+	assert.NoError(t, txn.Wait())
+
+	// the head is 11 on the first query and 14 once the
+	// transaction in block 12 has two confirmations
+	assert.Equal(t, *queries, 5)
+	assert.Equal(t, txn.Receipt().BlockNumber, uint64(12))
+	assert.Equal(t, txn.Receipt().BlockHash, web3.Hash{12})
+}
+
+func TestTxnWait_Timeout(t *testing.T) {
+	srv, _ := newWaitServer(t, 0, `"result":null`)
+	defer srv.Close()
+
+	txn := newWaitTxn(t, srv.URL)
+	assert.Equal(t, txn.waitTimeout, 5*time.Minute)
+
+	txn.SetWaitTimeout(50 * time.Millisecond)
+	assert.Equal(t, txn.Wait(), ErrWaitTimeout)
+	assert.Nil(t, txn.Receipt())
+
+	// the cancellation of the context is not a timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	txn = newWaitTxn(t, srv.URL).WithContext(ctx)
+	assert.Equal(t, txn.Wait(), context.Canceled)
+}
+
+func TestTxnWait_NotFound(t *testing.T) {
+	// some nodes return an error for the receipts of pending transactions
+	srv, queries := newWaitServer(t, 0, `"error":{"code":-32000,"message":"transaction not found"}`, receiptResult(5))
+	defer srv.Close()
+
+	txn := newWaitTxn(t, srv.URL)
+	assert.NoError(t, txn.Wait())
+	assert.Equal(t, *queries, 2)
+	assert.Equal(t, txn.Receipt().BlockNumber, uint64(5))
+
+	// any other error ends the wait
+	srv, queries = newWaitServer(t, 0, `"error":{"code":-32603,"message":"internal error"}`, receiptResult(5))
+	defer srv.Close()
+
+	txn = newWaitTxn(t, srv.URL)
+	assert.Error(t, txn.Wait())
+	assert.Equal(t, *queries, 1)
+	assert.Nil(t, txn.Receipt())
+}
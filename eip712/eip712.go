@@ -0,0 +1,518 @@
+package eip712
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	web3 "github.com/mover-code/golang-web3"
+	"github.com/mover-code/golang-web3/abi"
+	"github.com/mover-code/golang-web3/wallet"
+)
+
+// domainType is the name of the type of the domain
+const domainType = "EIP712Domain"
+
+// Field is a member of a struct type
+type Field struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Types are the struct types of the typed data by name
+type Types map[string][]*Field
+
+// Domain is the domain of the typed data. The fields that are not
+// set are not included in the domain separator.
+type Domain struct {
+	Name              string        `json:"name,omitempty"`
+	Version           string        `json:"version,omitempty"`
+	ChainID           *big.Int      `json:"chainId,omitempty"`
+	VerifyingContract *web3.Address `json:"verifyingContract,omitempty"`
+	Salt              *web3.Hash    `json:"salt,omitempty"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The chain id
+// is either a number or a string in decimal or hex format.
+func (d *Domain) UnmarshalJSON(data []byte) error {
+	type domainAlias Domain
+	var aux struct {
+		*domainAlias
+		ChainID json.RawMessage `json:"chainId,omitempty"`
+	}
+	aux.domainAlias = (*domainAlias)(d)
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if len(aux.ChainID) == 0 || string(aux.ChainID) == "null" {
+		return nil
+	}
+
+	str := string(aux.ChainID)
+	if unquoted, err := strconv.Unquote(str); err == nil {
+		str = unquoted
+	}
+	chainID, err := parseBigInt(str)
+	if err != nil {
+		return fmt.Errorf("failed to decode chainId: %v", err)
+	}
+	d.ChainID = chainID
+	return nil
+}
+
+// fields returns the fields of the domain type with the
+// values that are set in the domain
+func (d *Domain) fields() []*Field {
+	res := []*Field{}
+	if d.Name != "" {
+		res = append(res, &Field{Name: "name", Type: "string"})
+	}
+	if d.Version != "" {
+		res = append(res, &Field{Name: "version", Type: "string"})
+	}
+	if d.ChainID != nil {
+		res = append(res, &Field{Name: "chainId", Type: "uint256"})
+	}
+	if d.VerifyingContract != nil {
+		res = append(res, &Field{Name: "verifyingContract", Type: "address"})
+	}
+	if d.Salt != nil {
+		res = append(res, &Field{Name: "salt", Type: "bytes32"})
+	}
+	return res
+}
+
+func (d *Domain) values() map[string]interface{} {
+	res := map[string]interface{}{
+		"name":    d.Name,
+		"version": d.Version,
+	}
+	if d.ChainID != nil {
+		res["chainId"] = d.ChainID
+	}
+	if d.VerifyingContract != nil {
+		res["verifyingContract"] = *d.VerifyingContract
+	}
+	if d.Salt != nil {
+		res["salt"] = *d.Salt
+	}
+	return res
+}
+
+// TypedData is the typed structured data of EIP-712
+// in the format of eth_signTypedData_v4
+type TypedData struct {
+	Types       Types                  `json:"types"`
+	PrimaryType string                 `json:"primaryType"`
+	Domain      *Domain                `json:"domain"`
+	Message     map[string]interface{} `json:"message"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The numbers
+// of the message are decoded without losing precision.
+func (t *TypedData) UnmarshalJSON(data []byte) error {
+	type typedDataAlias TypedData
+	var aux struct {
+		*typedDataAlias
+		Message json.RawMessage `json:"message"`
+	}
+	aux.typedDataAlias = (*typedDataAlias)(t)
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	t.Message = nil
+	if len(aux.Message) != 0 {
+		dec := json.NewDecoder(bytes.NewReader(aux.Message))
+		dec.UseNumber()
+		if err := dec.Decode(&t.Message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseTypedData parses the typed data in json format
+func ParseTypedData(data []byte) (*TypedData, error) {
+	t := new(TypedData)
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *TypedData) domain() *Domain {
+	if t.Domain == nil {
+		return &Domain{}
+	}
+	return t.Domain
+}
+
+// types returns the types including the domain type, which is
+// derived from the domain if it is not declared
+func (t *TypedData) types() Types {
+	if _, ok := t.Types[domainType]; ok {
+		return t.Types
+	}
+	res := Types{}
+	for k, v := range t.Types {
+		res[k] = v
+	}
+	res[domainType] = t.domain().fields()
+	return res
+}
+
+var arrayRegexp = regexp.MustCompile(`^(.*)\[([0-9]*)\]$`)
+
+// baseType removes the array suffixes of a type (i.e. Person[][2] -> Person)
+func baseType(typ string) string {
+	for {
+		match := arrayRegexp.FindStringSubmatch(typ)
+		if match == nil {
+			return typ
+		}
+		typ = match[1]
+	}
+}
+
+func (t *TypedData) dependencies(primary string, found map[string]struct{}) {
+	primary = baseType(primary)
+	if _, ok := found[primary]; ok {
+		return
+	}
+	fields, ok := t.types()[primary]
+	if !ok {
+		return
+	}
+	found[primary] = struct{}{}
+	for _, field := range fields {
+		t.dependencies(field.Type, found)
+	}
+}
+
+// EncodeType returns the encoding of a struct type and the struct types
+// it references (i.e. 'Mail(Person from,Person to)Person(string name)')
+func (t *TypedData) EncodeType(primary string) (string, error) {
+	types := t.types()
+	if _, ok := types[primary]; !ok {
+		return "", fmt.Errorf("type %s not found", primary)
+	}
+
+	found := map[string]struct{}{}
+	t.dependencies(primary, found)
+	delete(found, primary)
+
+	deps := []string{}
+	for dep := range found {
+		deps = append(deps, dep)
+	}
+	sort.Strings(deps)
+	deps = append([]string{primary}, deps...)
+
+	var str strings.Builder
+	for _, dep := range deps {
+		fields := []string{}
+		for _, field := range types[dep] {
+			fields = append(fields, field.Type+" "+field.Name)
+		}
+		str.WriteString(dep + "(" + strings.Join(fields, ",") + ")")
+	}
+	return str.String(), nil
+}
+
+// TypeHash returns the hash of the encoding of a struct type
+func (t *TypedData) TypeHash(primary string) (web3.Hash, error) {
+	typ, err := t.EncodeType(primary)
+	if err != nil {
+		return web3.Hash{}, err
+	}
+	return keccak256([]byte(typ)), nil
+}
+
+// HashStruct returns the hash of a struct value of the given type
+func (t *TypedData) HashStruct(primary string, data map[string]interface{}) (web3.Hash, error) {
+	buf, err := t.encodeData(primary, data)
+	if err != nil {
+		return web3.Hash{}, err
+	}
+	return keccak256(buf), nil
+}
+
+// DomainSeparator returns the hash of the domain
+func (t *TypedData) DomainSeparator() (web3.Hash, error) {
+	return t.HashStruct(domainType, t.domain().values())
+}
+
+// Hash returns the hash of the typed data that is signed
+func (t *TypedData) Hash() (web3.Hash, error) {
+	domainSeparator, err := t.DomainSeparator()
+	if err != nil {
+		return web3.Hash{}, fmt.Errorf("failed to hash the domain: %v", err)
+	}
+	msg, err := t.HashStruct(t.PrimaryType, t.Message)
+	if err != nil {
+		return web3.Hash{}, fmt.Errorf("failed to hash the message: %v", err)
+	}
+	buf := append([]byte{0x19, 0x01}, domainSeparator[:]...)
+	buf = append(buf, msg[:]...)
+	return keccak256(buf), nil
+}
+
+func (t *TypedData) encodeData(primary string, data map[string]interface{}) ([]byte, error) {
+	typeHash, err := t.TypeHash(primary)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := append([]byte{}, typeHash[:]...)
+	for _, field := range t.types()[primary] {
+		val, ok := data[field.Name]
+		if !ok {
+			return nil, fmt.Errorf("field %s of %s not found", field.Name, primary)
+		}
+		res, err := t.encodeValue(field.Type, val)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode field %s of %s: %v", field.Name, primary, err)
+		}
+		buf = append(buf, res...)
+	}
+	return buf, nil
+}
+
+func (t *TypedData) encodeValue(typ string, val interface{}) ([]byte, error) {
+	// array of values
+	if match := arrayRegexp.FindStringSubmatch(typ); match != nil {
+		v := reflect.ValueOf(val)
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return nil, fmt.Errorf("expected array for type %s but found %T", typ, val)
+		}
+		if match[2] != "" {
+			if size, _ := strconv.Atoi(match[2]); size != v.Len() {
+				return nil, fmt.Errorf("expected %d elements for type %s but found %d", size, typ, v.Len())
+			}
+		}
+		var buf []byte
+		for i := 0; i < v.Len(); i++ {
+			res, err := t.encodeValue(match[1], v.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			buf = append(buf, res...)
+		}
+		hash := keccak256(buf)
+		return hash[:], nil
+	}
+
+	// nested struct
+	if _, ok := t.types()[typ]; ok {
+		data, ok := val.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected object for type %s but found %T", typ, val)
+		}
+		hash, err := t.HashStruct(typ, data)
+		if err != nil {
+			return nil, err
+		}
+		return hash[:], nil
+	}
+
+	switch typ {
+	case "string":
+		str, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string but found %T", val)
+		}
+		hash := keccak256([]byte(str))
+		return hash[:], nil
+
+	case "bytes":
+		buf, err := toBytes(val)
+		if err != nil {
+			return nil, err
+		}
+		hash := keccak256(buf)
+		return hash[:], nil
+	}
+
+	// atomic types are encoded with the abi
+	abiType, err := abi.NewType(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	switch abiType.Kind() {
+	case abi.KindBool:
+		v, err = toBool(val)
+
+	case abi.KindAddress:
+		v, err = toAddress(val)
+
+	case abi.KindInt, abi.KindUInt:
+		v, err = toNumber(abiType, val)
+
+	case abi.KindFixedBytes:
+		var buf []byte
+		if buf, err = toBytes(val); err == nil && len(buf) != abiType.Size() {
+			err = fmt.Errorf("expected %d bytes but found %d", abiType.Size(), len(buf))
+		}
+		v = buf
+
+	default:
+		return nil, fmt.Errorf("type %s not supported", typ)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return abi.Encode(v, abiType)
+}
+
+func toBool(val interface{}) (bool, error) {
+	switch obj := val.(type) {
+	case bool:
+		return obj, nil
+	case string:
+		return strconv.ParseBool(obj)
+	default:
+		return false, fmt.Errorf("expected bool but found %T", val)
+	}
+}
+
+func toAddress(val interface{}) (web3.Address, error) {
+	switch obj := val.(type) {
+	case web3.Address:
+		return obj, nil
+	case *web3.Address:
+		return *obj, nil
+	case string:
+		var addr web3.Address
+		if err := addr.UnmarshalText([]byte(obj)); err != nil {
+			return web3.Address{}, err
+		}
+		return addr, nil
+	default:
+		return web3.Address{}, fmt.Errorf("expected address but found %T", val)
+	}
+}
+
+func toBytes(val interface{}) ([]byte, error) {
+	switch obj := val.(type) {
+	case []byte:
+		return obj, nil
+	case string:
+		return hex.DecodeString(strings.TrimPrefix(obj, "0x"))
+	}
+
+	// fixed size arrays (i.e. web3.Hash)
+	v := reflect.ValueOf(val)
+	if v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8 {
+		buf := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(buf), v)
+		return buf, nil
+	}
+	return nil, fmt.Errorf("expected bytes but found %T", val)
+}
+
+func toNumber(typ *abi.Type, val interface{}) (*big.Int, error) {
+	var num *big.Int
+	var err error
+
+	switch obj := val.(type) {
+	case *big.Int:
+		num = obj
+	case json.Number:
+		num, err = parseBigInt(obj.String())
+	case string:
+		num, err = parseBigInt(obj)
+	case float64:
+		if obj != float64(int64(obj)) {
+			return nil, fmt.Errorf("expected integer but found %v", obj)
+		}
+		num = big.NewInt(int64(obj))
+	default:
+		v := reflect.ValueOf(val)
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			num = big.NewInt(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			num = new(big.Int).SetUint64(v.Uint())
+		default:
+			return nil, fmt.Errorf("expected number but found %T", val)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// check the number fits in the type
+	if typ.Kind() == abi.KindUInt {
+		if num.Sign() < 0 || num.BitLen() > typ.Size() {
+			return nil, fmt.Errorf("number %s overflows %s", num, typ)
+		}
+	} else {
+		max := new(big.Int).Lsh(big.NewInt(1), uint(typ.Size()-1))
+		min := new(big.Int).Neg(max)
+		if num.Cmp(min) < 0 || num.Cmp(max) >= 0 {
+			return nil, fmt.Errorf("number %s overflows %s", num, typ)
+		}
+	}
+	return num, nil
+}
+
+// parseBigInt parses a number in either decimal or hex format
+func parseBigInt(str string) (*big.Int, error) {
+	base := 10
+	if strings.HasPrefix(str, "0x") {
+		str, base = str[2:], 16
+	}
+	num, ok := new(big.Int).SetString(str, base)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse number '%s'", str)
+	}
+	return num, nil
+}
+
+func keccak256(buf []byte) (hash web3.Hash) {
+	copy(hash[:], wallet.Keccake256(buf))
+	return
+}
+
+// Sign signs the typed data with the key. As in eth_signTypedData_v4,
+// the signature is in [R || S || V] format with V being 27 or 28.
+func Sign(key *wallet.Key, typedData *TypedData) ([]byte, error) {
+	hash, err := typedData.Hash()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := key.Sign(hash[:])
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+// Recover returns the address of the account that signed the typed data.
+// The V value of the signature is either 0/1 or 27/28.
+func Recover(typedData *TypedData, signature []byte) (web3.Address, error) {
+	if len(signature) != 65 {
+		return web3.Address{}, fmt.Errorf("expected signature of 65 bytes but found %d", len(signature))
+	}
+	hash, err := typedData.Hash()
+	if err != nil {
+		return web3.Address{}, err
+	}
+
+	sig := append([]byte{}, signature...)
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	return wallet.Ecrecover(hash[:], sig)
+}
@@ -0,0 +1,164 @@
+package eip712
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	web3 "github.com/mover-code/golang-web3"
+	"github.com/mover-code/golang-web3/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+// mailTypedData is the example of the EIP-712 specification
+const mailTypedData = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": 1,
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {
+			"name": "Cow",
+			"wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"
+		},
+		"to": {
+			"name": "Bob",
+			"wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"
+		},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+func TestTypedData_Mail(t *testing.T) {
+	typedData, err := ParseTypedData([]byte(mailTypedData))
+	assert.NoError(t, err)
+
+	typ, err := typedData.EncodeType("Mail")
+	assert.NoError(t, err)
+	assert.Equal(t, typ, "Mail(Person from,Person to,string contents)Person(string name,address wallet)")
+
+	domain, err := typedData.DomainSeparator()
+	assert.NoError(t, err)
+	assert.Equal(t, domain.String(), "0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f")
+
+	msg, err := typedData.HashStruct("Mail", typedData.Message)
+	assert.NoError(t, err)
+	assert.Equal(t, msg.String(), "0xc52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e")
+
+	hash, err := typedData.Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, hash.String(), "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2")
+
+	// sign with the key of the specification
+	key, err := wallet.NewWalletFromPrivKey(wallet.Keccake256([]byte("cow")))
+	assert.NoError(t, err)
+	assert.Equal(t, key.Address(), web3.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"))
+
+	sig, err := Sign(key, typedData)
+	assert.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(sig), "4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d"+
+		"07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562"+"1c")
+
+	addr, err := Recover(typedData, sig)
+	assert.NoError(t, err)
+	assert.Equal(t, addr, key.Address())
+}
+
+func TestTypedData_Arrays(t *testing.T) {
+	verifyingContract := web3.Address{0x1}
+
+	typedData := &TypedData{
+		Types: Types{
+			"Order": {
+				{Name: "owner", Type: "address"},
+				{Name: "amounts", Type: "uint256[]"},
+				{Name: "items", Type: "Item[2]"},
+				{Name: "data", Type: "bytes"},
+			},
+			"Item": {
+				{Name: "id", Type: "uint8"},
+				{Name: "tag", Type: "bytes32"},
+			},
+		},
+		PrimaryType: "Order",
+		Domain: &Domain{
+			Name:              "Exchange",
+			ChainID:           big.NewInt(5),
+			VerifyingContract: &verifyingContract,
+		},
+		Message: map[string]interface{}{
+			"owner":   web3.Address{0x2},
+			"amounts": []interface{}{big.NewInt(1), "0x2", uint64(3)},
+			"items": []interface{}{
+				map[string]interface{}{"id": 1, "tag": web3.Hash{0x1}},
+				map[string]interface{}{"id": 2, "tag": web3.Hash{0x2}},
+			},
+			"data": []byte{0x1, 0x2},
+		},
+	}
+
+	typ, err := typedData.EncodeType("Order")
+	assert.NoError(t, err)
+	assert.Equal(t, typ, "Order(address owner,uint256[] amounts,Item[2] items,bytes data)Item(uint8 id,bytes32 tag)")
+
+	// the domain type is derived from the fields that are set
+	typ, err = typedData.EncodeType(domainType)
+	assert.NoError(t, err)
+	assert.Equal(t, typ, "EIP712Domain(string name,uint256 chainId,address verifyingContract)")
+
+	key, err := wallet.GenerateKey()
+	assert.NoError(t, err)
+
+	sig, err := Sign(key, typedData)
+	assert.NoError(t, err)
+
+	addr, err := Recover(typedData, sig)
+	assert.NoError(t, err)
+	assert.Equal(t, addr, key.Address())
+
+	// wrong number of elements in a fixed array
+	typedData.Message["items"] = []interface{}{}
+	_, err = typedData.Hash()
+	assert.Error(t, err)
+
+	// number out of range
+	typedData.Message["items"] = []interface{}{
+		map[string]interface{}{"id": 256, "tag": web3.Hash{}},
+		map[string]interface{}{"id": 1, "tag": web3.Hash{}},
+	}
+	_, err = typedData.Hash()
+	assert.Error(t, err)
+}
+
+func TestDomain_UnmarshalJSON(t *testing.T) {
+	cases := []string{
+		`{"chainId": 137}`,
+		`{"chainId": "137"}`,
+		`{"chainId": "0x89"}`,
+	}
+	for _, c := range cases {
+		var d Domain
+		assert.NoError(t, d.UnmarshalJSON([]byte(c)))
+		assert.Equal(t, d.ChainID.Uint64(), uint64(137))
+	}
+}
@@ -1,6 +1,7 @@
 package event
 
 import (
+    "context"
     "fmt"
     "reflect"
     "time"
@@ -90,52 +91,68 @@ func (d *MyContract) NewFilter(name ...interface{}) *web3.LogFilter {
 
 // It returns the current block number
 func (d *MyContract) NowBlock() web3.BlockNumber {
-    blockNumber, _ := d.Cli.Eth().BlockNumber()
+    return d.nowBlock(context.Background())
+}
+
+func (d *MyContract) nowBlock(ctx context.Context) web3.BlockNumber {
+    blockNumber, _ := d.Cli.Eth().WithContext(ctx).BlockNumber()
     return web3.BlockNumber(blockNumber)
 }
 
 // The above code is a Go function that is used to get the logs of a contract.
 func (d *MyContract) GetLogs(wrapper LoadWrapper, name ...interface{}) {
+    d.GetLogsContext(context.Background(), wrapper, name...)
+}
+
+// GetLogsContext polls the new logs of the contract until the context is done.
+func (d *MyContract) GetLogsContext(ctx context.Context, wrapper LoadWrapper, name ...interface{}) error {
     f := d.NewFilter(name...)
-    logsInfo := make(chan *web3.Log)
-    go func() {
-        n := d.NowBlock()
-        // init from now-5 block start catch logs
-        f.SetToUint64(uint64(n - 5))
-        old := *f.To
-        f.SetFromUint64(uint64(old))
-        for {
-            time.Sleep(time.Second * time.Duration(d.TimeDuration))
-            now := d.NowBlock()
-            if now > old {
-                logs, err := d.Cli.Eth().GetLogs(f)
-                if err == nil && len(logs) > 0 {
-                    for _, l := range logs {
-                        logsInfo <- l
-                    }
-                }
-                f.SetFromUint64(uint64(now))
-                f.SetToUint64(uint64(now))
-                old = now
-            }
-        }
-    }()
+    eth := d.Cli.Eth().WithContext(ctx)
 
+    n := d.nowBlock(ctx)
+    // init from now-5 block start catch logs
+    f.SetToUint64(uint64(n - 5))
+    old := *f.To
+    f.SetFromUint64(uint64(old))
     for {
         select {
-        case l := <-logsInfo:
-            d.ParseLogWithWrapper(l, wrapper, name...)
+        case <-time.After(time.Second * time.Duration(d.TimeDuration)):
+        case <-ctx.Done():
+            return ctx.Err()
+        }
+        now := d.nowBlock(ctx)
+        if now > old {
+            logs, err := eth.GetLogs(f)
+            if err == nil && len(logs) > 0 {
+                for _, l := range logs {
+                    d.ParseLogWithWrapper(l, wrapper, name...)
+                }
+            }
+            f.SetFromUint64(uint64(now))
+            f.SetToUint64(uint64(now))
+            old = now
         }
     }
 }
 
 // The above code is a function that is used to get the history logs of a contract.
 func (d *MyContract) GetHistoryLogs(start, step int64, wrapper LoadWrapper, name ...interface{}) {
+    d.GetHistoryLogsContext(context.Background(), start, step, wrapper, name...)
+}
+
+// GetHistoryLogsContext gets the history logs of the contract and stops if the context is done.
+func (d *MyContract) GetHistoryLogsContext(ctx context.Context, start, step int64, wrapper LoadWrapper, name ...interface{}) error {
     f := d.NewFilter(name...)
+    eth := d.Cli.Eth().WithContext(ctx)
+
     stop := false
-    block := d.NowBlock()
+    block := d.nowBlock(ctx)
     for {
-        time.Sleep(time.Millisecond * time.Duration(d.TimeDuration))
+        select {
+        case <-time.After(time.Millisecond * time.Duration(d.TimeDuration)):
+        case <-ctx.Done():
+            return ctx.Err()
+        }
         if start < int64(block) {
             newBlock := start + step
             if newBlock > int64(block) {
@@ -144,7 +161,7 @@ func (d *MyContract) GetHistoryLogs(start, step int64, wrapper LoadWrapper, name
             }
             f.SetFromUint64(uint64(start))
             f.SetToUint64(uint64(newBlock))
-            logs, err := d.Cli.Eth().GetLogs(f)
+            logs, err := eth.GetLogs(f)
             if err == nil && len(logs) > 0 {
                 for _, l := range logs {
                     d.ParseLogWithWrapper(l, wrapper, name...)
@@ -156,6 +173,7 @@ func (d *MyContract) GetHistoryLogs(start, step int64, wrapper LoadWrapper, name
             break
         }
     }
+    return nil
 }
 
 // A function that is used to call the contract method.
@@ -0,0 +1,286 @@
+package feeoracle
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	web3 "github.com/mover-code/golang-web3"
+	"github.com/mover-code/golang-web3/jsonrpc/codec"
+)
+
+// Strategy is the trade-off between the cost and
+// the inclusion time of a transaction
+type Strategy int
+
+const (
+	// Slow pays a low tip and a small margin over the base fee
+	Slow Strategy = iota
+
+	// Standard pays the median tip of the recent blocks
+	Standard
+
+	// Fast pays a high tip and a margin that covers several
+	// blocks of base fee increases
+	Fast
+)
+
+func (s Strategy) String() string {
+	switch s {
+	case Slow:
+		return "slow"
+	case Standard:
+		return "standard"
+	case Fast:
+		return "fast"
+	default:
+		return fmt.Sprintf("strategy(%d)", int(s))
+	}
+}
+
+const (
+	defaultBlocks = 20
+)
+
+// Provider are the eth1x methods required by the fee oracle
+type Provider interface {
+	FeeHistory(blocks uint64, newest web3.BlockNumber, percentiles []float64) (*web3.FeeHistory, error)
+	MaxPriorityFeePerGas() (*big.Int, error)
+	GasPrice() (uint64, error)
+}
+
+// StrategyConfig is how the fees of a strategy are computed
+type StrategyConfig struct {
+	// Percentile is the percentile of the priority fees paid in
+	// the recent blocks that is used as the tip
+	Percentile float64
+
+	// BaseFeeMultiplier is the percent of the projected base fee
+	// that is paid as the max fee on top of the tip
+	BaseFeeMultiplier uint64
+}
+
+// Config is the configuration of the fee oracle
+type Config struct {
+	// Blocks is the number of recent blocks that are sampled
+	Blocks uint64
+
+	// Strategies are the configs of each strategy
+	Strategies map[Strategy]*StrategyConfig
+
+	// MinPriorityFee is the minimum tip suggested
+	MinPriorityFee *big.Int
+}
+
+type ConfigOption func(*Config)
+
+func WithBlocks(b uint64) ConfigOption {
+	return func(c *Config) {
+		c.Blocks = b
+	}
+}
+
+func WithStrategy(s Strategy, config *StrategyConfig) ConfigOption {
+	return func(c *Config) {
+		c.Strategies[s] = config
+	}
+}
+
+func WithMinPriorityFee(f *big.Int) ConfigOption {
+	return func(c *Config) {
+		c.MinPriorityFee = f
+	}
+}
+
+// DefaultConfig returns the default fee oracle config
+func DefaultConfig() *Config {
+	return &Config{
+		Blocks: defaultBlocks,
+		Strategies: map[Strategy]*StrategyConfig{
+			Slow:     {Percentile: 10, BaseFeeMultiplier: 110},
+			Standard: {Percentile: 50, BaseFeeMultiplier: 125},
+			Fast:     {Percentile: 90, BaseFeeMultiplier: 200},
+		},
+		MinPriorityFee: big.NewInt(0),
+	}
+}
+
+// Fees are the suggested fees of a transaction. Chains without EIP-1559
+// only have the gas price.
+type Fees struct {
+	// BaseFee is the base fee of the next block
+	BaseFee *big.Int
+
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+
+	GasPrice uint64
+}
+
+// IsLegacy returns whether the fees are for a chain without EIP-1559
+func (f *Fees) IsLegacy() bool {
+	return f.MaxFeePerGas == nil
+}
+
+// Oracle suggests the fees of a transaction from the
+// fee history of the recent blocks
+type Oracle struct {
+	provider Provider
+	config   *Config
+}
+
+// NewOracle creates a new fee oracle
+func NewOracle(provider Provider, opts ...ConfigOption) *Oracle {
+	config := DefaultConfig()
+	for _, opt := range opts {
+		opt(config)
+	}
+	return &Oracle{
+		provider: provider,
+		config:   config,
+	}
+}
+
+// Fees returns the suggested fees for the strategy. The tip is the
+// percentile of the strategy of the tips paid in the recent blocks and
+// the max fee covers the trend of the base fee. If the chain does not
+// support EIP-1559 or the node does not support eth_feeHistory, the fees
+// only include the gas price of the node.
+func (o *Oracle) Fees(strategy Strategy) (*Fees, error) {
+	config, ok := o.config.Strategies[strategy]
+	if !ok {
+		return nil, fmt.Errorf("strategy '%s' not found", strategy)
+	}
+
+	history, err := o.provider.FeeHistory(o.config.Blocks, web3.Latest, []float64{config.Percentile})
+	if err != nil {
+		if !isUnsupported(err, "eth_feeHistory") {
+			return nil, err
+		}
+		return o.legacyFees()
+	}
+	if !hasBaseFee(history) {
+		return o.legacyFees()
+	}
+
+	tip, err := o.priorityFee(history)
+	if err != nil {
+		return nil, err
+	}
+	if tip.Cmp(o.config.MinPriorityFee) < 0 {
+		tip = new(big.Int).Set(o.config.MinPriorityFee)
+	}
+
+	baseFee := history.BaseFeePerGas[len(history.BaseFeePerGas)-1]
+
+	maxFee := projectBaseFee(history.BaseFeePerGas)
+	maxFee.Mul(maxFee, new(big.Int).SetUint64(config.BaseFeeMultiplier))
+	maxFee.Div(maxFee, big.NewInt(100))
+	maxFee.Add(maxFee, tip)
+
+	fees := &Fees{
+		BaseFee:              new(big.Int).Set(baseFee),
+		MaxFeePerGas:         maxFee,
+		MaxPriorityFeePerGas: tip,
+	}
+	return fees, nil
+}
+
+func (o *Oracle) legacyFees() (*Fees, error) {
+	gasPrice, err := o.provider.GasPrice()
+	if err != nil {
+		return nil, err
+	}
+	return &Fees{GasPrice: gasPrice}, nil
+}
+
+// priorityFee returns the median of the tips of the blocks with
+// transactions or, if there are none, the tip suggested by the node
+func (o *Oracle) priorityFee(history *web3.FeeHistory) (*big.Int, error) {
+	tips := []*big.Int{}
+	for indx, reward := range history.Reward {
+		if len(reward) == 0 {
+			continue
+		}
+		if indx < len(history.GasUsedRatio) && history.GasUsedRatio[indx] == 0 {
+			// empty block
+			continue
+		}
+		tips = append(tips, reward[0])
+	}
+	if len(tips) == 0 {
+		return o.provider.MaxPriorityFeePerGas()
+	}
+
+	sort.Slice(tips, func(i, j int) bool {
+		return tips[i].Cmp(tips[j]) < 0
+	})
+	return new(big.Int).Set(tips[len(tips)/2]), nil
+}
+
+// projectBaseFee returns the base fee of the next block. If the base
+// fee is above the average of the range, the trend is extended by the
+// same ratio.
+func projectBaseFee(baseFees []*big.Int) *big.Int {
+	next := new(big.Int).Set(baseFees[len(baseFees)-1])
+
+	avg := new(big.Int)
+	for _, fee := range baseFees {
+		avg.Add(avg, fee)
+	}
+	avg.Div(avg, big.NewInt(int64(len(baseFees))))
+
+	if avg.Sign() == 0 || next.Cmp(avg) <= 0 {
+		return next
+	}
+	next.Mul(next, baseFees[len(baseFees)-1])
+	return next.Div(next, avg)
+}
+
+func hasBaseFee(history *web3.FeeHistory) bool {
+	if history == nil || len(history.BaseFeePerGas) == 0 {
+		return false
+	}
+	for _, fee := range history.BaseFeePerGas {
+		if fee.Sign() != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// unsupportedMessages are the errors of the nodes for a method they
+// do not implement when they are about the method itself
+var unsupportedMessages = []string{
+	"does not exist",
+	"not supported",
+	"not available",
+}
+
+// isUnsupported returns whether the error is the rejection
+// of a method that the node does not implement
+func isUnsupported(err error, method string) bool {
+	msg := err.Error()
+	var obj *codec.ErrorObject
+	if errors.As(err, &obj) {
+		if obj.Code == -32601 {
+			return true
+		}
+		msg = obj.Message
+	}
+	msg = strings.ToLower(msg)
+	if strings.Contains(msg, "method not found") {
+		return true
+	}
+	if !strings.Contains(msg, strings.ToLower(method)) {
+		return false
+	}
+	for _, m := range unsupportedMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package feeoracle
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	web3 "github.com/mover-code/golang-web3"
+	"github.com/mover-code/golang-web3/jsonrpc/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockProvider struct {
+	baseFees []*big.Int
+	ratios   []float64
+	// rewards are the tips of each block sorted, the closest
+	// position to the percentile is the reward of the block
+	rewards  [][]*big.Int
+	tip      *big.Int
+	gasPrice uint64
+
+	// historyErr is the error of eth_feeHistory if it is set
+	historyErr error
+}
+
+func (m *mockProvider) FeeHistory(blocks uint64, newest web3.BlockNumber, percentiles []float64) (*web3.FeeHistory, error) {
+	if m.historyErr != nil {
+		return nil, m.historyErr
+	}
+	if m.baseFees == nil {
+		return nil, fmt.Errorf("the method eth_feeHistory does not exist/is not available")
+	}
+	history := &web3.FeeHistory{
+		BaseFeePerGas: m.baseFees,
+		GasUsedRatio:  m.ratios,
+	}
+	for _, rewards := range m.rewards {
+		indx := int(percentiles[0]/100*float64(len(rewards)-1) + 0.5)
+		history.Reward = append(history.Reward, []*big.Int{rewards[indx]})
+	}
+	return history, nil
+}
+
+func (m *mockProvider) MaxPriorityFeePerGas() (*big.Int, error) {
+	return m.tip, nil
+}
+
+func (m *mockProvider) GasPrice() (uint64, error) {
+	return m.gasPrice, nil
+}
+
+func bigInts(nums ...int64) []*big.Int {
+	res := []*big.Int{}
+	for _, num := range nums {
+		res = append(res, big.NewInt(num))
+	}
+	return res
+}
+
+func TestOracle_Strategies(t *testing.T) {
+	provider := &mockProvider{
+		baseFees: bigInts(100, 100, 100, 100),
+		ratios:   []float64{0.5, 0.5, 0.5},
+		rewards: [][]*big.Int{
+			bigInts(1, 5, 10),
+			bigInts(2, 6, 20),
+			bigInts(3, 7, 30),
+		},
+	}
+	o := NewOracle(provider)
+
+	cases := []struct {
+		strategy Strategy
+		tip      int64
+		maxFee   int64
+	}{
+		{Slow, 2, 110 + 2},
+		{Standard, 6, 125 + 6},
+		{Fast, 20, 200 + 20},
+	}
+	for _, c := range cases {
+		fees, err := o.Fees(c.strategy)
+		assert.NoError(t, err)
+		assert.False(t, fees.IsLegacy())
+		assert.Equal(t, fees.BaseFee, big.NewInt(100))
+		assert.Equal(t, fees.MaxPriorityFeePerGas, big.NewInt(c.tip))
+		assert.Equal(t, fees.MaxFeePerGas, big.NewInt(c.maxFee))
+	}
+
+	_, err := o.Fees(Strategy(10))
+	assert.Error(t, err)
+}
+
+func TestOracle_BaseFeeTrend(t *testing.T) {
+	// the base fee is rising, the average is 150
+	provider := &mockProvider{
+		baseFees: bigInts(100, 125, 150, 175, 200),
+		ratios:   []float64{1, 1, 1, 1},
+		rewards:  [][]*big.Int{bigInts(1), bigInts(1), bigInts(1), bigInts(1)},
+	}
+	o := NewOracle(provider)
+
+	fees, err := o.Fees(Slow)
+	assert.NoError(t, err)
+	assert.Equal(t, fees.BaseFee, big.NewInt(200))
+	// 200 * 200 / 150 = 266 and 266 * 110% = 292
+	assert.Equal(t, fees.MaxFeePerGas, big.NewInt(292+1))
+
+	// the base fee is falling, the next base fee is used
+	provider.baseFees = bigInts(200, 175, 150, 125, 100)
+
+	fees, err = o.Fees(Slow)
+	assert.NoError(t, err)
+	assert.Equal(t, fees.MaxFeePerGas, big.NewInt(110+1))
+}
+
+func TestOracle_EmptyBlocks(t *testing.T) {
+	provider := &mockProvider{
+		baseFees: bigInts(100, 100, 100),
+		ratios:   []float64{0, 0},
+		rewards:  [][]*big.Int{bigInts(0), bigInts(0)},
+		tip:      big.NewInt(3),
+	}
+
+	// the tip of the node is used
+	fees, err := NewOracle(provider).Fees(Standard)
+	assert.NoError(t, err)
+	assert.Equal(t, fees.MaxPriorityFeePerGas, big.NewInt(3))
+
+	// the tip is at least the minimum
+	fees, err = NewOracle(provider, WithMinPriorityFee(big.NewInt(5))).Fees(Standard)
+	assert.NoError(t, err)
+	assert.Equal(t, fees.MaxPriorityFeePerGas, big.NewInt(5))
+	assert.Equal(t, fees.MaxFeePerGas, big.NewInt(125+5))
+}
+
+func TestOracle_Legacy(t *testing.T) {
+	// the chain does not support eth_feeHistory
+	provider := &mockProvider{gasPrice: 1000}
+
+	fees, err := NewOracle(provider).Fees(Standard)
+	assert.NoError(t, err)
+	assert.True(t, fees.IsLegacy())
+	assert.Equal(t, fees.GasPrice, uint64(1000))
+
+	// the chain has no base fee
+	provider.baseFees = bigInts(0, 0)
+	provider.ratios = []float64{0.5}
+	provider.rewards = [][]*big.Int{bigInts(1)}
+
+	fees, err = NewOracle(provider).Fees(Standard)
+	assert.NoError(t, err)
+	assert.True(t, fees.IsLegacy())
+
+	// the node does not implement eth_feeHistory
+	provider.historyErr = &codec.ErrorObject{Code: -32601, Message: "Method not available"}
+
+	fees, err = NewOracle(provider).Fees(Standard)
+	assert.NoError(t, err)
+	assert.True(t, fees.IsLegacy())
+}
+
+func TestOracle_FeeHistoryError(t *testing.T) {
+	provider := &mockProvider{gasPrice: 1000}
+
+	// other errors are not a missing eth_feeHistory
+	provider.historyErr = &codec.ErrorObject{Code: -32000, Message: "request timed out"}
+	_, err := NewOracle(provider).Fees(Standard)
+	assert.Equal(t, err, provider.historyErr)
+
+	provider.historyErr = fmt.Errorf("context deadline exceeded")
+	_, err = NewOracle(provider).Fees(Standard)
+	assert.Equal(t, err, provider.historyErr)
+
+	// the errors that are not about the method are returned
+	provider.historyErr = &codec.ErrorObject{Code: -32000, Message: "header not found"}
+	_, err = NewOracle(provider).Fees(Standard)
+	assert.Equal(t, err, provider.historyErr)
+
+	provider.historyErr = &codec.ErrorObject{Code: -32000, Message: "block 0x10 does not exist"}
+	_, err = NewOracle(provider).Fees(Standard)
+	assert.Equal(t, err, provider.historyErr)
+
+	// the errors about the method are a missing eth_feeHistory
+	for _, msg := range []string{"Method not found", "eth_feeHistory is not supported"} {
+		provider.historyErr = &codec.ErrorObject{Code: -32000, Message: msg}
+		fees, err := NewOracle(provider).Fees(Standard)
+		assert.NoError(t, err)
+		assert.True(t, fees.IsLegacy())
+	}
+}
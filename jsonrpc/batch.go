@@ -0,0 +1,83 @@
+package jsonrpc
+
+import (
+	"context"
+
+	"github.com/mover-code/golang-web3/jsonrpc/transport"
+)
+
+const defaultBatchSize = 100
+
+// Batch queues several jsonrpc calls and sends them together
+type Batch struct {
+	client *Client
+	size   int
+	elems  []*transport.BatchElem
+}
+
+// Batch creates a new batch of calls
+func (c *Client) Batch() *Batch {
+	return &Batch{
+		client: c,
+		size:   defaultBatchSize,
+	}
+}
+
+// SetBatchSize sets the maximum number of calls sent in a single request.
+// Bigger batches are split in several requests.
+func (b *Batch) SetBatchSize(size int) *Batch {
+	if size > 0 {
+		b.size = size
+	}
+	return b
+}
+
+// Call queues a new call in the batch. The result is decoded into
+// out once the batch is done and the error of the call is set
+// in the returned element.
+func (b *Batch) Call(method string, out interface{}, params ...interface{}) *transport.BatchElem {
+	elem := &transport.BatchElem{
+		Method: method,
+		Params: params,
+		Out:    out,
+	}
+	b.elems = append(b.elems, elem)
+	return elem
+}
+
+// Len returns the number of queued calls
+func (b *Batch) Len() int {
+	return len(b.elems)
+}
+
+// Do sends the queued calls. The returned error only reports failures
+// of the whole batch, the error of each call is set in its element.
+// If the transport does not support batches, the calls are sent one by one.
+func (b *Batch) Do() error {
+	return b.DoContext(context.Background())
+}
+
+// DoContext sends the queued calls and stops if the context is done
+func (b *Batch) DoContext(ctx context.Context) error {
+	batchTransport, ok := b.client.transport.(transport.BatchTransport)
+	if !ok {
+		for _, elem := range b.elems {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			elem.Err = b.client.CallContext(ctx, elem.Method, elem.Out, elem.Params...)
+		}
+		return nil
+	}
+
+	for i := 0; i < len(b.elems); i += b.size {
+		end := i + b.size
+		if end > len(b.elems) {
+			end = len(b.elems)
+		}
+		if err := batchTransport.CallBatch(ctx, b.elems[i:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
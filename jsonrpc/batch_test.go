@@ -0,0 +1,109 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mover-code/golang-web3/jsonrpc/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+// batchHandler replies to a batch of requests in reverse order with the name
+// of the method as result, the method 'fail' returns an error.
+func batchHandler(t *testing.T, data []byte) []byte {
+	var reqs []codec.Request
+	assert.NoError(t, json.Unmarshal(data, &reqs))
+
+	resps := []codec.Response{}
+	for i := len(reqs) - 1; i >= 0; i-- {
+		resp := codec.Response{ID: reqs[i].ID}
+		if reqs[i].Method == "fail" {
+			resp.Error = &codec.ErrorObject{Code: -32000, Message: "failed"}
+		} else {
+			resp.Result, _ = json.Marshal(reqs[i].Method)
+		}
+		resps = append(resps, resp)
+	}
+	res, err := json.Marshal(resps)
+	assert.NoError(t, err)
+	return res
+}
+
+func testBatch(t *testing.T, c *Client, num int) {
+	b := c.Batch().SetBatchSize(10)
+
+	outs := make([]string, num)
+	for i := 0; i < num; i++ {
+		method := "a"
+		if i%3 == 0 {
+			method = "fail"
+		}
+		b.Call(method, &outs[i], i)
+	}
+	assert.Equal(t, b.Len(), num)
+	assert.NoError(t, b.Do())
+
+	for i, elem := range b.elems {
+		if i%3 == 0 {
+			assert.Error(t, elem.Err)
+		} else {
+			assert.NoError(t, elem.Err)
+			assert.Equal(t, outs[i], "a")
+		}
+	}
+}
+
+func TestBatch_HTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		w.Write(batchHandler(t, data))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	assert.NoError(t, err)
+	defer c.Close()
+
+	testBatch(t, c, 25)
+}
+
+func TestBatch_IPC(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "batch-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "test.ipc")
+	lis, err := net.Listen("unix", path)
+	assert.NoError(t, err)
+	defer lis.Close()
+
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		dec := json.NewDecoder(conn)
+		for {
+			var data json.RawMessage
+			if err := dec.Decode(&data); err != nil {
+				return
+			}
+			conn.Write(batchHandler(t, data))
+		}
+	}()
+
+	c, err := NewClient(path)
+	assert.NoError(t, err)
+	defer c.Close()
+
+	testBatch(t, c, 25)
+}
@@ -1,6 +1,8 @@
 package jsonrpc
 
 import (
+	"context"
+
 	"github.com/mover-code/golang-web3/jsonrpc/transport"
 )
 
@@ -19,18 +21,24 @@ type endpoints struct {
 
 // NewClient creates a new client
 func NewClient(addr string) (*Client, error) {
-	c := &Client{}
-	c.endpoints.w = &Web3{c}
-	c.endpoints.e = &Eth{c}
-	c.endpoints.n = &Net{c}
-	c.endpoints.d = &Debug{c}
-
 	t, err := transport.NewTransport(addr)
 	if err != nil {
 		return nil, err
 	}
-	c.transport = t
-	return c, nil
+	return NewClientWithTransport(t), nil
+}
+
+// NewClientWithTransport creates a new client with a specific transport
+// (i.e. a transport.Multi to balance the requests between several nodes)
+func NewClientWithTransport(t transport.Transport) *Client {
+	c := &Client{
+		transport: t,
+	}
+	c.endpoints.w = &Web3{c: c}
+	c.endpoints.e = &Eth{c: c}
+	c.endpoints.n = &Net{c: c}
+	c.endpoints.d = &Debug{c: c}
+	return c
 }
 
 // Close closes the tranport
@@ -42,3 +50,33 @@ func (c *Client) Close() error {
 func (c *Client) Call(method string, out interface{}, params ...interface{}) error {
 	return c.transport.Call(method, out, params...)
 }
+
+// CallContext makes a jsonrpc call that returns once the context is done.
+// If the transport cannot cancel the request, it keeps running in the background.
+func (c *Client) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	if t, ok := c.transport.(transport.ContextTransport); ok {
+		return t.CallContext(ctx, method, out, params...)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- c.transport.Call(method, out, params...)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func contextOrBackground(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
@@ -0,0 +1,74 @@
+package jsonrpc
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallContext_HTTP(t *testing.T) {
+	doneCh := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the node never replies
+		<-doneCh
+	}))
+	defer srv.Close()
+	defer close(doneCh)
+
+	c, err := NewClient(srv.URL)
+	assert.NoError(t, err)
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err = c.Eth().WithContext(ctx).BlockNumber()
+	assert.Equal(t, err, context.DeadlineExceeded)
+}
+
+func TestCallContext_IPC(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "ctx-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "test.ipc")
+	lis, err := net.Listen("unix", path)
+	assert.NoError(t, err)
+	defer lis.Close()
+
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		// read the requests but never reply
+		ioutil.ReadAll(conn)
+	}()
+
+	c, err := NewClient(path)
+	assert.NoError(t, err)
+	defer c.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	var out string
+	err = c.CallContext(ctx, "eth_blockNumber", &out)
+	assert.Equal(t, err, context.Canceled)
+
+	// batches are cancelled too
+	b := c.Batch()
+	b.Call("eth_blockNumber", &out)
+	assert.Equal(t, b.DoContext(ctx), context.Canceled)
+}
@@ -1,8 +1,10 @@
 package codec
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 // Request is a jsonrpc request
@@ -25,6 +27,35 @@ type ErrorObject struct {
 	Code    int         `json:"code"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
+
+	// rawData is the data as sent by the node
+	rawData json.RawMessage
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+// and keeps the raw bytes of the data
+func (e *ErrorObject) UnmarshalJSON(buf []byte) error {
+	type errorObject ErrorObject
+
+	var obj struct {
+		errorObject
+		Data json.RawMessage `json:"data,omitempty"`
+	}
+	if err := json.Unmarshal(buf, &obj); err != nil {
+		return err
+	}
+	e.Code = obj.Code
+	e.Message = obj.Message
+	e.Data = nil
+	e.rawData = nil
+
+	if len(obj.Data) != 0 && string(obj.Data) != "null" {
+		if err := json.Unmarshal(obj.Data, &e.Data); err != nil {
+			return err
+		}
+		e.rawData = obj.Data
+	}
+	return nil
 }
 
 // Subscription is a jsonrpc subscription
@@ -41,3 +72,38 @@ func (e *ErrorObject) Error() string {
 	}
 	return string(data)
 }
+
+// RevertData returns the data of a reverted execution included in the error.
+// The data is either a hex string or an object with a 'data' field.
+func (e *ErrorObject) RevertData() ([]byte, bool) {
+	raw := e.rawData
+	if len(raw) == 0 {
+		if e.Data == nil {
+			return nil, false
+		}
+		// the error was not decoded from a response
+		var err error
+		if raw, err = json.Marshal(e.Data); err != nil {
+			return nil, false
+		}
+	}
+
+	var str string
+	if err := json.Unmarshal(raw, &str); err != nil {
+		var obj struct {
+			Data string `json:"data"`
+		}
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return nil, false
+		}
+		str = obj.Data
+	}
+	if !strings.HasPrefix(str, "0x") {
+		return nil, false
+	}
+	buf, err := hex.DecodeString(str[2:])
+	if err != nil {
+		return nil, false
+	}
+	return buf, true
+}
@@ -0,0 +1,57 @@
+package codec
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorObject_RevertData(t *testing.T) {
+	cases := []struct {
+		input string
+		data  interface{}
+		ok    bool
+	}{
+		{
+			`{"code":3,"message":"execution reverted","data":"0x0102"}`,
+			"0x0102",
+			true,
+		},
+		{
+			`{"code":-32015,"message":"vm error","data":{"data":"0x0102","reason":"x"}}`,
+			map[string]interface{}{"data": "0x0102", "reason": "x"},
+			true,
+		},
+		{
+			`{"code":-32000,"message":"failed"}`,
+			nil,
+			false,
+		},
+		{
+			`{"code":-32000,"message":"failed","data":"not hex"}`,
+			"not hex",
+			false,
+		},
+	}
+	for _, c := range cases {
+		var obj ErrorObject
+		assert.NoError(t, json.Unmarshal([]byte(c.input), &obj))
+
+		// the data is still decoded as an interface
+		assert.Equal(t, obj.Data, c.data)
+
+		data, ok := obj.RevertData()
+		assert.Equal(t, ok, c.ok)
+		if ok {
+			assert.Equal(t, data, []byte{0x1, 0x2})
+		}
+	}
+
+	// the errors not decoded from a response use the data field
+	obj := &ErrorObject{Code: 3, Data: "0x0102"}
+	data, ok := obj.RevertData()
+	assert.True(t, ok)
+	assert.Equal(t, data, []byte{0x1, 0x2})
+	assert.Equal(t, obj.Error(), `{"code":3,"message":"","data":"0x0102"}`)
+}
@@ -1,9 +1,14 @@
 package jsonrpc
 
-import "github.com/mover-code/golang-web3"
+import (
+	"context"
+
+	"github.com/mover-code/golang-web3"
+)
 
 type Debug struct {
-	c *Client
+	c   *Client
+	ctx context.Context
 }
 
 // Eth returns the reference to the eth namespace
@@ -11,6 +16,16 @@ func (c *Client) Debug() *Debug {
 	return c.endpoints.d
 }
 
+// WithContext returns a copy of the debug namespace that sends
+// the requests with the given context
+func (d *Debug) WithContext(ctx context.Context) *Debug {
+	return &Debug{c: d.c, ctx: ctx}
+}
+
+func (d *Debug) call(method string, out interface{}, params ...interface{}) error {
+	return d.c.CallContext(contextOrBackground(d.ctx), method, out, params...)
+}
+
 type TransactionTrace struct {
 	Gas         uint64
 	ReturnValue string
@@ -30,6 +45,6 @@ type StructLogs struct {
 
 func (d *Debug) TraceTransaction(hash web3.Hash) (*TransactionTrace, error) {
 	var res *TransactionTrace
-	err := d.c.Call("debug_traceTransaction", &res, hash)
+	err := d.call("debug_traceTransaction", &res, hash)
 	return res, err
 }
@@ -1,6 +1,7 @@
 package jsonrpc
 
 import (
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -11,7 +12,8 @@ import (
 
 // Eth is the eth namespace
 type Eth struct {
-	c *Client
+	c   *Client
+	ctx context.Context
 }
 
 // Eth returns the reference to the eth namespace
@@ -19,10 +21,20 @@ func (c *Client) Eth() *Eth {
 	return c.endpoints.e
 }
 
+// WithContext returns a copy of the eth namespace that sends
+// the requests with the given context
+func (e *Eth) WithContext(ctx context.Context) *Eth {
+	return &Eth{c: e.c, ctx: ctx}
+}
+
+func (e *Eth) call(method string, out interface{}, params ...interface{}) error {
+	return e.c.CallContext(contextOrBackground(e.ctx), method, out, params...)
+}
+
 // GetCode returns the code of a contract
 func (e *Eth) GetCode(addr web3.Address, block web3.BlockNumberOrHash) (string, error) {
 	var res string
-	if err := e.c.Call("eth_getCode", &res, addr, block.Location()); err != nil {
+	if err := e.call("eth_getCode", &res, addr, block.Location()); err != nil {
 		return "", err
 	}
 	return res, nil
@@ -31,7 +43,7 @@ func (e *Eth) GetCode(addr web3.Address, block web3.BlockNumberOrHash) (string,
 // Accounts returns a list of addresses owned by client.
 func (e *Eth) Accounts() ([]web3.Address, error) {
 	var out []web3.Address
-	if err := e.c.Call("eth_accounts", &out); err != nil {
+	if err := e.call("eth_accounts", &out); err != nil {
 		return nil, err
 	}
 	return out, nil
@@ -40,14 +52,28 @@ func (e *Eth) Accounts() ([]web3.Address, error) {
 // GetStorageAt returns the value from a storage position at a given address.
 func (e *Eth) GetStorageAt(addr web3.Address, slot web3.Hash, block web3.BlockNumberOrHash) (web3.Hash, error) {
 	var hash web3.Hash
-	err := e.c.Call("eth_getStorageAt", &hash, addr, slot, block.Location())
+	err := e.call("eth_getStorageAt", &hash, addr, slot, block.Location())
 	return hash, err
 }
 
+// GetProof returns the merkle proof of an account and of the given
+// storage slots. It can be verified with the trie package against the
+// state root of the block.
+func (e *Eth) GetProof(addr web3.Address, slots []web3.Hash, block web3.BlockNumberOrHash) (*web3.AccountProof, error) {
+	if slots == nil {
+		slots = []web3.Hash{}
+	}
+	var out *web3.AccountProof
+	if err := e.call("eth_getProof", &out, addr, slots, block.Location()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BlockNumber returns the number of most recent block.
 func (e *Eth) BlockNumber() (uint64, error) {
 	var out string
-	if err := e.c.Call("eth_blockNumber", &out); err != nil {
+	if err := e.call("eth_blockNumber", &out); err != nil {
 		return 0, err
 	}
 	return parseUint64orHex(out)
@@ -56,7 +82,7 @@ func (e *Eth) BlockNumber() (uint64, error) {
 // GetBlockByNumber returns information about a block by block number.
 func (e *Eth) GetBlockByNumber(i web3.BlockNumber, full bool) (*web3.Block, error) {
 	var b *web3.Block
-	if err := e.c.Call("eth_getBlockByNumber", &b, i.String(), full); err != nil {
+	if err := e.call("eth_getBlockByNumber", &b, i.String(), full); err != nil {
 		return nil, err
 	}
 	return b, nil
@@ -65,7 +91,7 @@ func (e *Eth) GetBlockByNumber(i web3.BlockNumber, full bool) (*web3.Block, erro
 // GetBlockByHash returns information about a block by hash.
 func (e *Eth) GetBlockByHash(hash web3.Hash, full bool) (*web3.Block, error) {
 	var b *web3.Block
-	if err := e.c.Call("eth_getBlockByHash", &b, hash, full); err != nil {
+	if err := e.call("eth_getBlockByHash", &b, hash, full); err != nil {
 		return nil, err
 	}
 	return b, nil
@@ -74,7 +100,7 @@ func (e *Eth) GetBlockByHash(hash web3.Hash, full bool) (*web3.Block, error) {
 // GetFilterChanges returns the filter changes for log filters
 func (e *Eth) GetFilterChanges(id string) ([]*web3.Log, error) {
 	var raw string
-	err := e.c.Call("eth_getFilterChanges", &raw, id)
+	err := e.call("eth_getFilterChanges", &raw, id)
 	if err != nil {
 		return nil, err
 	}
@@ -88,14 +114,14 @@ func (e *Eth) GetFilterChanges(id string) ([]*web3.Log, error) {
 // GetTransactionByHash returns a transaction by his hash
 func (e *Eth) GetTransactionByHash(hash web3.Hash) (*web3.Transaction, error) {
 	var txn *web3.Transaction
-	err := e.c.Call("eth_getTransactionByHash", &txn, hash)
+	err := e.call("eth_getTransactionByHash", &txn, hash)
 	return txn, err
 }
 
 // GetFilterChangesBlock returns the filter changes for block filters
 func (e *Eth) GetFilterChangesBlock(id string) ([]web3.Hash, error) {
 	var raw string
-	err := e.c.Call("eth_getFilterChanges", &raw, id)
+	err := e.call("eth_getFilterChanges", &raw, id)
 	if err != nil {
 		return nil, err
 	}
@@ -109,21 +135,21 @@ func (e *Eth) GetFilterChangesBlock(id string) ([]web3.Hash, error) {
 // NewFilter creates a new log filter
 func (e *Eth) NewFilter(filter *web3.LogFilter) (string, error) {
 	var id string
-	err := e.c.Call("eth_newFilter", &id, filter)
+	err := e.call("eth_newFilter", &id, filter)
 	return id, err
 }
 
 // NewBlockFilter creates a new block filter
 func (e *Eth) NewBlockFilter() (string, error) {
 	var id string
-	err := e.c.Call("eth_newBlockFilter", &id, nil)
+	err := e.call("eth_newBlockFilter", &id, nil)
 	return id, err
 }
 
 // UninstallFilter uninstalls a filter
 func (e *Eth) UninstallFilter(id string) (bool, error) {
 	var res bool
-	err := e.c.Call("eth_uninstallFilter", &res, id)
+	err := e.call("eth_uninstallFilter", &res, id)
 	return res, err
 }
 
@@ -131,7 +157,7 @@ func (e *Eth) UninstallFilter(id string) (bool, error) {
 func (e *Eth) SendRawTransaction(data []byte) (web3.Hash, error) {
 	var hash web3.Hash
 	hexData := "0x" + hex.EncodeToString(data)
-	err := e.c.Call("eth_sendRawTransaction", &hash, hexData)
+	err := e.call("eth_sendRawTransaction", &hash, hexData)
 	return hash, err
 }
 
@@ -143,21 +169,21 @@ func (e *Eth) SenSignTransaction(txn *web3.Transaction) (web3.Hash, error) {
 // SendTransaction creates new message call transaction or a contract creation.
 func (e *Eth) SendTransaction(txn *web3.Transaction) (web3.Hash, error) {
 	var hash web3.Hash
-	err := e.c.Call("eth_sendTransaction", &hash, txn)
+	err := e.call("eth_sendTransaction", &hash, txn)
 	return hash, err
 }
 
 // GetTransactionReceipt returns the receipt of a transaction by transaction hash.
 func (e *Eth) GetTransactionReceipt(hash web3.Hash) (*web3.Receipt, error) {
 	var receipt *web3.Receipt
-	err := e.c.Call("eth_getTransactionReceipt", &receipt, hash)
+	err := e.call("eth_getTransactionReceipt", &receipt, hash)
 	return receipt, err
 }
 
 // GetNonce returns the nonce of the account
 func (e *Eth) GetNonce(addr web3.Address, blockNumber web3.BlockNumberOrHash) (uint64, error) {
 	var nonce string
-	if err := e.c.Call("eth_getTransactionCount", &nonce, addr, blockNumber.Location()); err != nil {
+	if err := e.call("eth_getTransactionCount", &nonce, addr, blockNumber.Location()); err != nil {
 		return 0, err
 	}
 	return parseUint64orHex(nonce)
@@ -166,7 +192,7 @@ func (e *Eth) GetNonce(addr web3.Address, blockNumber web3.BlockNumberOrHash) (u
 // GetBalance returns the balance of the account of given address.
 func (e *Eth) GetBalance(addr web3.Address, blockNumber web3.BlockNumberOrHash) (*big.Int, error) {
 	var out string
-	if err := e.c.Call("eth_getBalance", &out, addr, blockNumber.Location()); err != nil {
+	if err := e.call("eth_getBalance", &out, addr, blockNumber.Location()); err != nil {
 		return nil, err
 	}
 	b, ok := new(big.Int).SetString(out[2:], 16)
@@ -179,16 +205,39 @@ func (e *Eth) GetBalance(addr web3.Address, blockNumber web3.BlockNumberOrHash)
 // GasPrice returns the current price per gas in wei.
 func (e *Eth) GasPrice() (uint64, error) {
 	var out string
-	if err := e.c.Call("eth_gasPrice", &out); err != nil {
+	if err := e.call("eth_gasPrice", &out); err != nil {
 		return 0, err
 	}
 	return parseUint64orHex(out)
 }
 
+// MaxPriorityFeePerGas returns the suggested priority fee per gas in
+// wei for EIP-1559 transactions.
+func (e *Eth) MaxPriorityFeePerGas() (*big.Int, error) {
+	var out string
+	if err := e.call("eth_maxPriorityFeePerGas", &out); err != nil {
+		return nil, err
+	}
+	return parseBigInt(out), nil
+}
+
+// FeeHistory returns the base fee and the priority fees at the given
+// percentiles of a range of blocks that ends in the newest block.
+func (e *Eth) FeeHistory(blocks uint64, newest web3.BlockNumber, percentiles []float64) (*web3.FeeHistory, error) {
+	if percentiles == nil {
+		percentiles = []float64{}
+	}
+	var out *web3.FeeHistory
+	if err := e.call("eth_feeHistory", &out, encodeUintToHex(blocks), newest.String(), percentiles); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Call executes a new message call immediately without creating a transaction on the block chain.
 func (e *Eth) Call(msg *web3.CallMsg, block web3.BlockNumber) (string, error) {
 	var out string
-	if err := e.c.Call("eth_call", &out, msg, block.String()); err != nil {
+	if err := e.call("eth_call", &out, msg, block.String()); err != nil {
 		return "", err
 	}
 	return out, nil
@@ -200,7 +249,7 @@ func (e *Eth) EstimateGasContract(bin []byte) (uint64, error) {
 	msg := map[string]interface{}{
 		"data": "0x" + hex.EncodeToString(bin),
 	}
-	if err := e.c.Call("eth_estimateGas", &out, msg); err != nil {
+	if err := e.call("eth_estimateGas", &out, msg); err != nil {
 		return 0, err
 	}
 	return parseUint64orHex(out)
@@ -209,16 +258,37 @@ func (e *Eth) EstimateGasContract(bin []byte) (uint64, error) {
 // EstimateGas generates and returns an estimate of how much gas is necessary to allow the transaction to complete.
 func (e *Eth) EstimateGas(msg *web3.CallMsg) (uint64, error) {
 	var out string
-	if err := e.c.Call("eth_estimateGas", &out, msg); err != nil {
+	if err := e.call("eth_estimateGas", &out, msg); err != nil {
 		return 0, err
 	}
 	return parseUint64orHex(out)
 }
 
+// CreateAccessList generates an access list for a message call along with
+// the gas used by the call once the access list is applied.
+func (e *Eth) CreateAccessList(msg *web3.CallMsg, block web3.BlockNumberOrHash) (web3.AccessList, uint64, error) {
+	var out struct {
+		AccessList web3.AccessList `json:"accessList"`
+		GasUsed    string          `json:"gasUsed"`
+		Error      string          `json:"error"`
+	}
+	if err := e.call("eth_createAccessList", &out, msg, block.Location()); err != nil {
+		return nil, 0, err
+	}
+	if out.Error != "" {
+		return nil, 0, fmt.Errorf("failed to create access list: %s", out.Error)
+	}
+	gasUsed, err := parseUint64orHex(out.GasUsed)
+	if err != nil {
+		return nil, 0, err
+	}
+	return out.AccessList, gasUsed, nil
+}
+
 // GetLogs returns an array of all logs matching a given filter object
 func (e *Eth) GetLogs(filter *web3.LogFilter) ([]*web3.Log, error) {
 	var out []*web3.Log
-	if err := e.c.Call("eth_getLogs", &out, filter); err != nil {
+	if err := e.call("eth_getLogs", &out, filter); err != nil {
 		return nil, err
 	}
 	return out, nil
@@ -227,7 +297,7 @@ func (e *Eth) GetLogs(filter *web3.LogFilter) ([]*web3.Log, error) {
 // ChainID returns the id of the chain
 func (e *Eth) ChainID() (*big.Int, error) {
 	var out string
-	if err := e.c.Call("eth_chainId", &out); err != nil {
+	if err := e.call("eth_chainId", &out); err != nil {
 		return nil, err
 	}
 	return parseBigInt(out), nil
@@ -163,6 +163,24 @@ func TestEthGasPrice(t *testing.T) {
 	})
 }
 
+func TestEthFeeHistory(t *testing.T) {
+	testutil.MultiAddr(t, nil, func(s *testutil.TestServer, addr string) {
+		c, _ := NewClient(addr)
+		defer c.Close()
+
+		_, err := c.Eth().MaxPriorityFeePerGas()
+		assert.NoError(t, err)
+
+		assert.NoError(t, s.ProcessBlock())
+
+		history, err := c.Eth().FeeHistory(1, web3.Latest, []float64{50})
+		assert.NoError(t, err)
+		assert.Len(t, history.BaseFeePerGas, 2)
+		assert.Len(t, history.GasUsedRatio, 1)
+		assert.Len(t, history.Reward, 1)
+	})
+}
+
 func TestEthSendTransaction(t *testing.T) {
 	s := testutil.NewTestServer(t, nil)
 	defer s.Close()
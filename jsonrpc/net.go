@@ -1,8 +1,11 @@
 package jsonrpc
 
+import "context"
+
 // Net is the net namespace
 type Net struct {
-	c *Client
+	c   *Client
+	ctx context.Context
 }
 
 // Net returns the reference to the net namespace
@@ -10,10 +13,20 @@ func (c *Client) Net() *Net {
 	return c.endpoints.n
 }
 
+// WithContext returns a copy of the net namespace that sends
+// the requests with the given context
+func (n *Net) WithContext(ctx context.Context) *Net {
+	return &Net{c: n.c, ctx: ctx}
+}
+
+func (n *Net) call(method string, out interface{}, params ...interface{}) error {
+	return n.c.CallContext(contextOrBackground(n.ctx), method, out, params...)
+}
+
 // Version returns the current network id
 func (n *Net) Version() (uint64, error) {
 	var out string
-	if err := n.c.Call("net_version", &out); err != nil {
+	if err := n.call("net_version", &out); err != nil {
 		return 0, err
 	}
 	return parseUint64orHex(out)
@@ -22,14 +35,14 @@ func (n *Net) Version() (uint64, error) {
 // Listening returns true if client is actively listening for network connections
 func (n *Net) Listening() (bool, error) {
 	var out bool
-	err := n.c.Call("net_listening", &out)
+	err := n.call("net_listening", &out)
 	return out, err
 }
 
 // PeerCount returns number of peers currently connected to the client
 func (n *Net) PeerCount() (uint64, error) {
 	var out string
-	if err := n.c.Call("net_peerCount", &out); err != nil {
+	if err := n.call("net_peerCount", &out); err != nil {
 		return 0, err
 	}
 	return parseUint64orHex(out)
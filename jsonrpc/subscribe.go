@@ -1,8 +1,12 @@
 package jsonrpc
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 
+	web3 "github.com/mover-code/golang-web3"
 	"github.com/mover-code/golang-web3/jsonrpc/transport"
 )
 
@@ -12,12 +16,247 @@ func (c *Client) SubscriptionEnabled() bool {
 	return ok
 }
 
-// Subscribe starts a new subscription
-func (c *Client) Subscribe(method string, callback func(b []byte)) (func() error, error) {
+// Subscribe starts a new subscription. The params are sent
+// after the name of the subscription (i.e. the filter of 'logs').
+func (c *Client) Subscribe(method string, callback func(b []byte), params ...interface{}) (func() error, error) {
 	pub, ok := c.transport.(transport.PubSubTransport)
 	if !ok {
 		return nil, fmt.Errorf("Transport does not support the subscribe method")
 	}
-	close, err := pub.Subscribe(method, callback)
+	close, err := pub.Subscribe(method, callback, params...)
 	return close, err
 }
+
+// ConnectionEvents returns a channel with the disconnection and reconnection
+// events of the transport. It can be used to backfill the notifications
+// lost while the connection was down. The returned function stops the events
+// and closes the channel.
+func (c *Client) ConnectionEvents() (<-chan *transport.ConnectionEvent, func(), error) {
+	t, ok := c.transport.(transport.ReconnectTransport)
+	if !ok {
+		return nil, nil, fmt.Errorf("Transport does not support reconnections")
+	}
+	ch, unsubscribe := t.ConnectionEvents()
+	return ch, unsubscribe, nil
+}
+
+// size of the buffer of the typed subscription channels
+const subscriptionBuffer = 16
+
+// Subscription is a typed subscription created with one of the
+// Subscribe* helpers. The channel of the notifications is not closed
+// after Unsubscribe.
+type Subscription struct {
+	cancel  func() error
+	errCh   chan error
+	closeCh chan struct{}
+	once    sync.Once
+}
+
+func newSubscription() *Subscription {
+	return &Subscription{
+		errCh:   make(chan error, 1),
+		closeCh: make(chan struct{}),
+	}
+}
+
+// Err returns a channel with the errors to decode the notifications
+func (s *Subscription) Err() <-chan error {
+	return s.errCh
+}
+
+// Unsubscribe stops the subscription
+func (s *Subscription) Unsubscribe() error {
+	var err error
+	s.once.Do(func() {
+		close(s.closeCh)
+		err = s.cancel()
+	})
+	return err
+}
+
+func (s *Subscription) sendErr(err error) {
+	select {
+	case s.errCh <- err:
+	default:
+	}
+}
+
+func (c *Client) subscribeWith(sub *Subscription, method string, handle func(b []byte) error, params ...interface{}) error {
+	cancel, err := c.Subscribe(method, func(b []byte) {
+		if err := handle(b); err != nil {
+			sub.sendErr(err)
+		}
+	}, params...)
+	if err != nil {
+		return err
+	}
+	sub.cancel = cancel
+	return nil
+}
+
+// SubscribeLogs subscribes to the logs that match the filter
+func (c *Client) SubscribeLogs(filter *web3.LogFilter) (<-chan *web3.Log, *Subscription, error) {
+	ch := make(chan *web3.Log, subscriptionBuffer)
+	sub := newSubscription()
+
+	err := c.subscribeWith(sub, "logs", func(b []byte) error {
+		log := new(web3.Log)
+		if err := log.UnmarshalJSON(b); err != nil {
+			return err
+		}
+		select {
+		case ch <- log:
+		case <-sub.closeCh:
+		}
+		return nil
+	}, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ch, sub, nil
+}
+
+// SubscribeNewHeads subscribes to the new blocks of the chain. The
+// blocks only include the header without the transactions.
+func (c *Client) SubscribeNewHeads() (<-chan *web3.Block, *Subscription, error) {
+	ch := make(chan *web3.Block, subscriptionBuffer)
+	sub := newSubscription()
+
+	err := c.subscribeWith(sub, "newHeads", func(b []byte) error {
+		block := new(web3.Block)
+		if err := block.UnmarshalJSON(b); err != nil {
+			return err
+		}
+		select {
+		case ch <- block:
+		case <-sub.closeCh:
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return ch, sub, nil
+}
+
+// SubscribeNewPendingTransactions subscribes to the hashes
+// of the transactions that enter the pool
+func (c *Client) SubscribeNewPendingTransactions() (<-chan web3.Hash, *Subscription, error) {
+	ch := make(chan web3.Hash, subscriptionBuffer)
+	sub := newSubscription()
+
+	err := c.subscribeWith(sub, "newPendingTransactions", func(b []byte) error {
+		var hash web3.Hash
+		if err := json.Unmarshal(b, &hash); err != nil {
+			return err
+		}
+		select {
+		case ch <- hash:
+		case <-sub.closeCh:
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return ch, sub, nil
+}
+
+// SubscribeNewPendingTransactionsFull subscribes to the full transactions
+// that enter the pool. Not all the nodes support this subscription.
+func (c *Client) SubscribeNewPendingTransactionsFull() (<-chan *web3.Transaction, *Subscription, error) {
+	ch := make(chan *web3.Transaction, subscriptionBuffer)
+	sub := newSubscription()
+
+	err := c.subscribeWith(sub, "newPendingTransactions", func(b []byte) error {
+		txn := new(web3.Transaction)
+		if err := txn.UnmarshalJSON(b); err != nil {
+			return err
+		}
+		select {
+		case ch <- txn:
+		case <-sub.closeCh:
+		}
+		return nil
+	}, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ch, sub, nil
+}
+
+// SyncStatus is the synchronization status of the node
+type SyncStatus struct {
+	Syncing       bool
+	StartingBlock uint64
+	CurrentBlock  uint64
+	HighestBlock  uint64
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The status
+// is either false or an object with the progress of the synchronization
+// (optionally wrapped in a 'status' field).
+func (s *SyncStatus) UnmarshalJSON(buf []byte) error {
+	var syncing bool
+	if err := json.Unmarshal(buf, &syncing); err == nil {
+		*s = SyncStatus{Syncing: syncing}
+		return nil
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(buf, &obj); err != nil {
+		return err
+	}
+	if status, ok := obj["status"]; ok {
+		if err := json.Unmarshal(status, &obj); err != nil {
+			return err
+		}
+	}
+
+	s.Syncing = true
+	fields := map[string]*uint64{
+		"startingblock": &s.StartingBlock,
+		"currentblock":  &s.CurrentBlock,
+		"highestblock":  &s.HighestBlock,
+	}
+	for k, v := range obj {
+		dst, ok := fields[strings.ToLower(k)]
+		if !ok {
+			continue
+		}
+		// the numbers are either hex strings or plain numbers
+		var str string
+		if err := json.Unmarshal(v, &str); err != nil {
+			str = string(v)
+		}
+		num, err := parseUint64orHex(str)
+		if err != nil {
+			return fmt.Errorf("failed to decode %s: %v", k, err)
+		}
+		*dst = num
+	}
+	return nil
+}
+
+// SubscribeSyncing subscribes to the changes in the synchronization status of the node
+func (c *Client) SubscribeSyncing() (<-chan *SyncStatus, *Subscription, error) {
+	ch := make(chan *SyncStatus, subscriptionBuffer)
+	sub := newSubscription()
+
+	err := c.subscribeWith(sub, "syncing", func(b []byte) error {
+		status := new(SyncStatus)
+		if err := status.UnmarshalJSON(b); err != nil {
+			return err
+		}
+		select {
+		case ch <- status:
+		case <-sub.closeCh:
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return ch, sub, nil
+}
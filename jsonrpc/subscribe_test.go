@@ -1,11 +1,17 @@
 package jsonrpc
 
 import (
-	"github.com/mover-code/golang-web3"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/gorilla/websocket"
+	"github.com/mover-code/golang-web3"
+	"github.com/mover-code/golang-web3/jsonrpc/codec"
 	"github.com/mover-code/golang-web3/testutil"
 	"github.com/stretchr/testify/assert"
 )
@@ -70,3 +76,185 @@ func TestSubscribeNewHead(t *testing.T) {
 		assert.Error(t, cancel())
 	})
 }
+
+// newSubscriptionServer creates a websocket server that replies to each
+// eth_subscribe request with the notifications returned by the handler
+func newSubscriptionServer(t *testing.T, handler func(params []json.RawMessage) []string) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var req codec.Request
+			assert.NoError(t, json.Unmarshal(data, &req))
+
+			resp := codec.Response{ID: req.ID}
+			if req.Method == "eth_unsubscribe" {
+				resp.Result = []byte("true")
+				data, _ = json.Marshal(resp)
+				conn.WriteMessage(websocket.TextMessage, data)
+				continue
+			}
+
+			var params []json.RawMessage
+			assert.NoError(t, json.Unmarshal(req.Params, &params))
+
+			resp.Result = []byte(`"0x1"`)
+			data, _ = json.Marshal(resp)
+			conn.WriteMessage(websocket.TextMessage, data)
+
+			for _, result := range handler(params) {
+				notification := `{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0x1","result":` + result + `}}`
+				conn.WriteMessage(websocket.TextMessage, []byte(notification))
+			}
+		}
+	}))
+}
+
+func newSubscriptionClient(t *testing.T, srv *httptest.Server) *Client {
+	c, err := NewClient("ws://" + strings.TrimPrefix(srv.URL, "http://"))
+	assert.NoError(t, err)
+	return c
+}
+
+func TestSubscribeLogs(t *testing.T) {
+	srv := newSubscriptionServer(t, func(params []json.RawMessage) []string {
+		assert.Len(t, params, 2)
+		assert.Equal(t, string(params[0]), `"logs"`)
+
+		// the filter is sent as the second param
+		var filter map[string]interface{}
+		assert.NoError(t, json.Unmarshal(params[1], &filter))
+		assert.Equal(t, filter["address"], []interface{}{"0x0100000000000000000000000000000000000000", "0x0200000000000000000000000000000000000000"})
+
+		return []string{`{"address":"0x0100000000000000000000000000000000000000","topics":[],"data":"0x01","blockNumber":"0x1","transactionHash":"0x0000000000000000000000000000000000000000000000000000000000000000","transactionIndex":"0x0","blockHash":"0x0000000000000000000000000000000000000000000000000000000000000000","logIndex":"0x0","removed":false}`}
+	})
+	defer srv.Close()
+
+	c := newSubscriptionClient(t, srv)
+	defer c.Close()
+
+	ch, sub, err := c.SubscribeLogs(&web3.LogFilter{Address: []web3.Address{addr0, addr1}})
+	assert.NoError(t, err)
+
+	select {
+	case log := <-ch:
+		assert.Equal(t, log.Address, addr0)
+		assert.Equal(t, log.BlockNumber, uint64(1))
+	case err := <-sub.Err():
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+
+	assert.NoError(t, sub.Unsubscribe())
+}
+
+func TestSubscribeLogsOrder(t *testing.T) {
+	num := 200
+	srv := newSubscriptionServer(t, func(params []json.RawMessage) []string {
+		logs := []string{}
+		for i := 0; i < num; i++ {
+			logs = append(logs, fmt.Sprintf(`{"address":"0x0100000000000000000000000000000000000000","topics":[],"data":"0x","blockNumber":"0x1","transactionHash":"0x0000000000000000000000000000000000000000000000000000000000000000","transactionIndex":"0x0","blockHash":"0x0000000000000000000000000000000000000000000000000000000000000000","logIndex":"0x%x","removed":false}`, i))
+		}
+		return logs
+	})
+	defer srv.Close()
+
+	c := newSubscriptionClient(t, srv)
+	defer c.Close()
+
+	ch, sub, err := c.SubscribeLogs(&web3.LogFilter{})
+	assert.NoError(t, err)
+
+	// the logs are received in the order sent by the node
+	for i := 0; i < num; i++ {
+		select {
+		case log := <-ch:
+			assert.Equal(t, log.LogIndex, uint64(i))
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+	}
+
+	assert.NoError(t, sub.Unsubscribe())
+}
+
+func TestSubscribeNewPendingTransactions(t *testing.T) {
+	srv := newSubscriptionServer(t, func(params []json.RawMessage) []string {
+		assert.Len(t, params, 1)
+		return []string{`"0x0100000000000000000000000000000000000000000000000000000000000000"`}
+	})
+	defer srv.Close()
+
+	c := newSubscriptionClient(t, srv)
+	defer c.Close()
+
+	ch, sub, err := c.SubscribeNewPendingTransactions()
+	assert.NoError(t, err)
+
+	select {
+	case hash := <-ch:
+		assert.Equal(t, hash, web3.Hash{0x1})
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+
+	assert.NoError(t, sub.Unsubscribe())
+}
+
+func TestSubscribeSyncing(t *testing.T) {
+	srv := newSubscriptionServer(t, func(params []json.RawMessage) []string {
+		return []string{`{"syncing":true,"status":{"startingBlock":1,"currentBlock":"0x2","highestBlock":3}}`}
+	})
+	defer srv.Close()
+
+	c := newSubscriptionClient(t, srv)
+	defer c.Close()
+
+	ch, sub, err := c.SubscribeSyncing()
+	assert.NoError(t, err)
+
+	select {
+	case status := <-ch:
+		assert.Equal(t, status, &SyncStatus{Syncing: true, StartingBlock: 1, CurrentBlock: 2, HighestBlock: 3})
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+
+	assert.NoError(t, sub.Unsubscribe())
+
+	// not syncing
+	var status SyncStatus
+	assert.NoError(t, status.UnmarshalJSON([]byte("false")))
+	assert.False(t, status.Syncing)
+}
+
+func TestSubscribeDecodeError(t *testing.T) {
+	srv := newSubscriptionServer(t, func(params []json.RawMessage) []string {
+		return []string{`"bad"`}
+	})
+	defer srv.Close()
+
+	c := newSubscriptionClient(t, srv)
+	defer c.Close()
+
+	_, sub, err := c.SubscribeNewHeads()
+	assert.NoError(t, err)
+
+	select {
+	case err := <-sub.Err():
+		assert.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+}
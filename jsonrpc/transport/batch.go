@@ -0,0 +1,73 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mover-code/golang-web3/jsonrpc/codec"
+)
+
+// BatchTransport is a transport that allows to send several
+// jsonrpc requests in a single round trip
+type BatchTransport interface {
+	// CallBatch makes a batch of jsonrpc requests
+	CallBatch(ctx context.Context, elems []*BatchElem) error
+}
+
+// BatchElem is a single request of a batch
+type BatchElem struct {
+	// Method is the jsonrpc method
+	Method string
+
+	// Params are the params of the request
+	Params []interface{}
+
+	// Out is the target where the result is decoded
+	Out interface{}
+
+	// Err is the error of the request once the batch is done
+	Err error
+}
+
+func (e *BatchElem) request(id uint64) (*codec.Request, error) {
+	request := &codec.Request{
+		JsonRPC: "2.0",
+		ID:      id,
+		Method:  e.Method,
+	}
+	if len(e.Params) > 0 {
+		data, err := json.Marshal(e.Params)
+		if err != nil {
+			return nil, err
+		}
+		request.Params = data
+	}
+	return request, nil
+}
+
+func (e *BatchElem) setResult(buf []byte, err error) {
+	if err != nil {
+		e.Err = err
+		return
+	}
+	if e.Out == nil {
+		return
+	}
+	e.Err = json.Unmarshal(buf, e.Out)
+}
+
+// encodeBatch encodes the elements with consecutive ids starting from seq
+func encodeBatch(seq uint64, elems []*BatchElem) ([]byte, error) {
+	requests := make([]*codec.Request, len(elems))
+	for indx, elem := range elems {
+		request, err := elem.request(seq + uint64(indx))
+		if err != nil {
+			return nil, err
+		}
+		requests[indx] = request
+	}
+	return json.Marshal(requests)
+}
+
+var errBatchNoResponse = fmt.Errorf("batch response not found")
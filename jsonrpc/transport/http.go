@@ -1,7 +1,9 @@
 package transport
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 
 	"github.com/mover-code/golang-web3/jsonrpc/codec"
 
@@ -28,6 +30,11 @@ func (h *HTTP) Close() error {
 
 // Call implements the transport interface
 func (h *HTTP) Call(method string, out interface{}, params ...interface{}) error {
+	return h.CallContext(context.Background(), method, out, params...)
+}
+
+// CallContext implements the ContextTransport interface
+func (h *HTTP) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
 	// Encode json-rpc request
 	request := codec.Request{
 		JsonRPC: "2.0",
@@ -45,24 +52,14 @@ func (h *HTTP) Call(method string, out interface{}, params ...interface{}) error
 		return err
 	}
 
-	req := fasthttp.AcquireRequest()
-	res := fasthttp.AcquireResponse()
-
-	defer fasthttp.ReleaseRequest(req)
-	defer fasthttp.ReleaseResponse(res)
-
-	req.SetRequestURI(h.addr)
-	req.Header.SetMethod("POST")
-	req.Header.SetContentType("application/json")
-	req.SetBody(raw)
-
-	if err := h.client.Do(req, res); err != nil {
+	body, err := h.do(ctx, raw)
+	if err != nil {
 		return err
 	}
 
 	// Decode json-rpc response
 	var response codec.Response
-	if err := json.Unmarshal(res.Body(), &response); err != nil {
+	if err := json.Unmarshal(body, &response); err != nil {
 		return err
 	}
 	if response.Error != nil {
@@ -74,3 +71,105 @@ func (h *HTTP) Call(method string, out interface{}, params ...interface{}) error
 	}
 	return nil
 }
+
+type httpResult struct {
+	body []byte
+	err  error
+}
+
+// do sends the raw request and returns the body of the response. The request
+// is done in a separate goroutine since fasthttp does not support contexts,
+// if the context is done before the request finishes the response is dropped.
+func (h *HTTP) do(ctx context.Context, raw []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	resCh := make(chan httpResult, 1)
+	go func() {
+		req := fasthttp.AcquireRequest()
+		res := fasthttp.AcquireResponse()
+
+		defer fasthttp.ReleaseRequest(req)
+		defer fasthttp.ReleaseResponse(res)
+
+		req.SetRequestURI(h.addr)
+		req.Header.SetMethod("POST")
+		req.Header.SetContentType("application/json")
+		req.SetBody(raw)
+
+		var err error
+		if deadline, ok := ctx.Deadline(); ok {
+			err = h.client.DoDeadline(req, res, deadline)
+			if err == fasthttp.ErrTimeout {
+				// the deadline of the context was reached
+				err = context.DeadlineExceeded
+			}
+		} else {
+			err = h.client.Do(req, res)
+		}
+		if err != nil {
+			resCh <- httpResult{err: err}
+			return
+		}
+		resCh <- httpResult{body: append([]byte{}, res.Body()...)}
+	}()
+
+	select {
+	case res := <-resCh:
+		return res.body, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// CallBatch implements the BatchTransport interface
+func (h *HTTP) CallBatch(ctx context.Context, elems []*BatchElem) error {
+	if len(elems) == 0 {
+		return nil
+	}
+	raw, err := encodeBatch(1, elems)
+	if err != nil {
+		return err
+	}
+
+	body, err := h.do(ctx, raw)
+	if err != nil {
+		return err
+	}
+
+	// Decode json-rpc responses. Some nodes reply with a
+	// single error object if the whole batch fails.
+	var responses []codec.Response
+	if err := json.Unmarshal(body, &responses); err != nil {
+		var response codec.Response
+		if err := json.Unmarshal(body, &response); err != nil {
+			return err
+		}
+		if response.Error != nil {
+			return response.Error
+		}
+		return fmt.Errorf("unexpected batch response")
+	}
+
+	found := make([]bool, len(elems))
+	for _, response := range responses {
+		indx := int(response.ID) - 1
+		if indx < 0 || indx >= len(elems) || found[indx] {
+			continue
+		}
+		found[indx] = true
+
+		if response.Error != nil {
+			elems[indx].setResult(nil, response.Error)
+		} else {
+			elems[indx].setResult(response.Result, nil)
+		}
+	}
+	for indx, elem := range elems {
+		if !found[indx] {
+			elem.Err = errBatchNoResponse
+		}
+	}
+	return nil
+}
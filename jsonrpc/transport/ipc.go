@@ -1,23 +1,32 @@
 package transport
 
 import (
+	"context"
 	"encoding/json"
 	"net"
 )
 
-func newIPC(addr string) (Transport, error) {
-	conn, err := net.Dial("unix", addr)
+func newIPC(ctx context.Context, addr string) (Transport, error) {
+	dial := func(ctx context.Context) (Codec, error) {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "unix", addr)
+		if err != nil {
+			return nil, err
+		}
+		codec := &ipcCodec{
+			buf:  json.RawMessage{},
+			conn: conn,
+			dec:  json.NewDecoder(conn),
+		}
+		return codec, nil
+	}
+	codec, err := dial(ctx)
 	if err != nil {
 		return nil, err
 	}
-
-	codec := &ipcCodec{
-		buf:  json.RawMessage{},
-		conn: conn,
-		dec:  json.NewDecoder(conn),
-	}
-
-	return newStream(codec)
+	return newStream(codec, func() (Codec, error) {
+		return dial(context.Background())
+	})
 }
 
 type ipcCodec struct {
@@ -0,0 +1,605 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mover-code/golang-web3/jsonrpc/codec"
+)
+
+// Strategy is the strategy to select the endpoint of a request
+type Strategy int
+
+const (
+	// RoundRobin sends the requests to each endpoint in turns
+	RoundRobin Strategy = iota
+
+	// LowestLatency balances the requests between the endpoints in inverse
+	// proportion to their latency, the fastest endpoints get most of them
+	LowestLatency
+)
+
+const (
+	defaultMaxFailures         = 3
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultHealthCheckTimeout  = 5 * time.Second
+
+	// weight of the last measure in the average latency
+	latencyWeight = 0.2
+)
+
+// ErrNoEndpoints happens when there are no endpoints available
+var ErrNoEndpoints = fmt.Errorf("no endpoints available")
+
+// MultiConfig is the configuration of the multi endpoint transport
+type MultiConfig struct {
+	// Strategy is the strategy to select the endpoint of each request
+	Strategy Strategy
+
+	// MaxFailures is the number of consecutive failures after which
+	// the endpoint is considered dead
+	MaxFailures uint64
+
+	// HealthCheckInterval is the interval between health checks,
+	// a zero value disables the health checks
+	HealthCheckInterval time.Duration
+
+	// HighestBlock only uses the endpoints that are in sync
+	// with the highest block seen
+	HighestBlock bool
+
+	// MaxBlockLag is the number of blocks an endpoint can be behind
+	// the highest block if HighestBlock is enabled
+	MaxBlockLag uint64
+}
+
+// DefaultMultiConfig returns the default configuration
+func DefaultMultiConfig() *MultiConfig {
+	return &MultiConfig{
+		Strategy:            RoundRobin,
+		MaxFailures:         defaultMaxFailures,
+		HealthCheckInterval: defaultHealthCheckInterval,
+	}
+}
+
+// MultiOption is an option to configure the multi endpoint transport
+type MultiOption func(*MultiConfig)
+
+// WithStrategy sets the strategy to select the endpoints
+func WithStrategy(s Strategy) MultiOption {
+	return func(c *MultiConfig) {
+		c.Strategy = s
+	}
+}
+
+// WithMaxFailures sets the number of consecutive failures to mark an endpoint as dead
+func WithMaxFailures(n uint64) MultiOption {
+	return func(c *MultiConfig) {
+		c.MaxFailures = n
+	}
+}
+
+// WithHealthCheckInterval sets the interval between health checks
+func WithHealthCheckInterval(d time.Duration) MultiOption {
+	return func(c *MultiConfig) {
+		c.HealthCheckInterval = d
+	}
+}
+
+// WithHighestBlock only sends the requests to the endpoints that are at most
+// maxLag blocks behind the highest block seen in any endpoint
+func WithHighestBlock(maxLag uint64) MultiOption {
+	return func(c *MultiConfig) {
+		c.HighestBlock = true
+		c.MaxBlockLag = maxLag
+	}
+}
+
+type endpoint struct {
+	url string
+
+	lock      sync.Mutex
+	transport Transport
+	failures  uint64
+	latency   time.Duration
+	block     uint64
+}
+
+func (e *endpoint) getTransport(ctx context.Context) (Transport, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.transport == nil {
+		// websocket and ipc endpoints are dialed lazily
+		// to not fail if the node is down at start
+		t, err := newTransport(ctx, e.url)
+		if err != nil {
+			return nil, err
+		}
+		e.transport = t
+	}
+	return e.transport, nil
+}
+
+func (e *endpoint) isAlive(maxFailures uint64) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return maxFailures == 0 || e.failures < maxFailures
+}
+
+func (e *endpoint) success(latency time.Duration) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.failures = 0
+	if e.latency == 0 {
+		e.latency = latency
+	} else {
+		e.latency = time.Duration(latencyWeight*float64(latency) + (1-latencyWeight)*float64(e.latency))
+	}
+}
+
+func (e *endpoint) failure() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.failures++
+
+	// drop the connection of stream transports, it is dialed again on the next request
+	if _, ok := e.transport.(*stream); ok {
+		e.transport.Close()
+		e.transport = nil
+	}
+}
+
+func (e *endpoint) close() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.transport == nil {
+		return nil
+	}
+	return e.transport.Close()
+}
+
+// Multi is a transport that sends the requests to several endpoints. Requests
+// are balanced between the endpoints that are alive and the idempotent ones are
+// retried in a different endpoint if they fail. The requests of a filter are
+// sent to the endpoint that created it.
+type Multi struct {
+	config    *MultiConfig
+	endpoints []*endpoint
+	seq       uint64
+	closeCh   chan struct{}
+	closeOnce sync.Once
+
+	// filters are the endpoints where each filter was created
+	filtersLock sync.Mutex
+	filters     map[string]*endpoint
+}
+
+// NewMulti creates a new transport for the given urls
+func NewMulti(urls []string, opts ...MultiOption) (*Multi, error) {
+	if len(urls) == 0 {
+		return nil, ErrNoEndpoints
+	}
+
+	config := DefaultMultiConfig()
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	m := &Multi{
+		config:  config,
+		closeCh: make(chan struct{}),
+		filters: map[string]*endpoint{},
+	}
+	for _, url := range urls {
+		m.endpoints = append(m.endpoints, &endpoint{url: url})
+	}
+
+	if config.HealthCheckInterval != 0 {
+		// run the first check before any request to know the latest block and latency
+		m.healthCheck()
+		go m.runHealthCheck()
+	}
+	return m, nil
+}
+
+// Close implements the transport interface
+func (m *Multi) Close() error {
+	var lastErr error
+	m.closeOnce.Do(func() {
+		close(m.closeCh)
+
+		for _, e := range m.endpoints {
+			if err := e.close(); err != nil {
+				lastErr = err
+			}
+		}
+	})
+	return lastErr
+}
+
+func (m *Multi) runHealthCheck() {
+	for {
+		select {
+		case <-time.After(m.config.HealthCheckInterval):
+			m.healthCheck()
+		case <-m.closeCh:
+			return
+		}
+	}
+}
+
+// healthCheck queries the block number of all the endpoints
+func (m *Multi) healthCheck() {
+	var wg sync.WaitGroup
+	for _, e := range m.endpoints {
+		wg.Add(1)
+		go func(e *endpoint) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), defaultHealthCheckTimeout)
+			defer cancel()
+
+			var out string
+			if err := m.callEndpoint(ctx, e, "eth_blockNumber", &out); err != nil {
+				return
+			}
+			num, err := parseBlockNumber(out)
+			if err != nil {
+				return
+			}
+			e.lock.Lock()
+			e.block = num
+			e.lock.Unlock()
+		}(e)
+	}
+	wg.Wait()
+}
+
+func parseBlockNumber(str string) (uint64, error) {
+	if !strings.HasPrefix(str, "0x") {
+		return 0, fmt.Errorf("bad block number %s", str)
+	}
+	return strconv.ParseUint(str[2:], 16, 64)
+}
+
+// candidates returns the endpoints that can receive requests
+// excluding the ones already tried
+func (m *Multi) candidates(tried map[*endpoint]struct{}) []*endpoint {
+	alive := []*endpoint{}
+	for _, e := range m.endpoints {
+		if _, ok := tried[e]; ok {
+			continue
+		}
+		if e.isAlive(m.config.MaxFailures) {
+			alive = append(alive, e)
+		}
+	}
+	if len(alive) == 0 {
+		// all the endpoints are dead, try them anyway
+		for _, e := range m.endpoints {
+			if _, ok := tried[e]; !ok {
+				alive = append(alive, e)
+			}
+		}
+		return alive
+	}
+	if !m.config.HighestBlock {
+		return alive
+	}
+
+	// only use the endpoints that are close to the highest block
+	// of the alive endpoints, the dead ones may report any block
+	highest := uint64(0)
+	for _, e := range m.endpoints {
+		if !e.isAlive(m.config.MaxFailures) {
+			continue
+		}
+		e.lock.Lock()
+		if e.block > highest {
+			highest = e.block
+		}
+		e.lock.Unlock()
+	}
+	inSync := []*endpoint{}
+	for _, e := range alive {
+		e.lock.Lock()
+		if e.block+m.config.MaxBlockLag >= highest {
+			inSync = append(inSync, e)
+		}
+		e.lock.Unlock()
+	}
+	if len(inSync) == 0 {
+		// the endpoints in sync were already tried, a lagging
+		// endpoint is better than no endpoint at all
+		return alive
+	}
+	return inSync
+}
+
+func (m *Multi) selectEndpoint(tried map[*endpoint]struct{}) (*endpoint, error) {
+	candidates := m.candidates(tried)
+	if len(candidates) == 0 {
+		return nil, ErrNoEndpoints
+	}
+
+	if m.config.Strategy == LowestLatency {
+		return selectByLatency(candidates), nil
+	}
+
+	indx := atomic.AddUint64(&m.seq, 1) % uint64(len(candidates))
+	return candidates[indx], nil
+}
+
+// selectByLatency picks an endpoint at random with a probability inversely
+// proportional to its latency. The endpoints without latency are picked
+// first to measure it.
+func selectByLatency(candidates []*endpoint) *endpoint {
+	weights := make([]float64, len(candidates))
+	total := float64(0)
+	for indx, e := range candidates {
+		e.lock.Lock()
+		latency := e.latency
+		e.lock.Unlock()
+
+		if latency == 0 {
+			return e
+		}
+		weights[indx] = 1 / float64(latency)
+		total += weights[indx]
+	}
+
+	r := rand.Float64() * total
+	for indx, weight := range weights {
+		if r < weight {
+			return candidates[indx]
+		}
+		r -= weight
+	}
+	return candidates[len(candidates)-1]
+}
+
+// callEndpoint sends the request to the endpoint and updates its stats
+func (m *Multi) callEndpoint(ctx context.Context, e *endpoint, method string, out interface{}, params ...interface{}) error {
+	t, err := e.getTransport(ctx)
+	if err != nil {
+		e.failure()
+		return err
+	}
+
+	now := time.Now()
+	err = callContext(ctx, t, method, out, params...)
+	if isEndpointFailure(ctx, err) {
+		e.failure()
+	} else {
+		e.success(time.Since(now))
+	}
+	return err
+}
+
+// isEndpointFailure returns true if the error was caused by the endpoint
+// and not by the request itself
+func isEndpointFailure(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	switch err.(type) {
+	case *codec.ErrorObject, *json.UnmarshalTypeError:
+		// the node replied to the request
+		return false
+	}
+	return true
+}
+
+// Call implements the transport interface
+func (m *Multi) Call(method string, out interface{}, params ...interface{}) error {
+	return m.CallContext(context.Background(), method, out, params...)
+}
+
+// CallContext implements the ContextTransport interface
+func (m *Multi) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	if _, ok := filterMethods[method]; ok {
+		return m.callFilter(ctx, method, out, params...)
+	}
+
+	var lastErr error
+	tried := map[*endpoint]struct{}{}
+	for {
+		e, err := m.selectEndpoint(tried)
+		if err != nil {
+			if lastErr != nil {
+				return lastErr
+			}
+			return err
+		}
+		tried[e] = struct{}{}
+
+		err = m.callEndpoint(ctx, e, method, out, params...)
+		if _, ok := newFilterMethods[method]; ok && err == nil {
+			m.addFilter(e, out)
+		}
+		lastErr = err
+		if !isEndpointFailure(ctx, err) || !isIdempotent(method) || len(tried) == len(m.endpoints) {
+			return err
+		}
+	}
+}
+
+// callFilter sends the request of a filter to the endpoint that created it
+func (m *Multi) callFilter(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	if len(params) == 0 {
+		return fmt.Errorf("filter id not found in the params of %s", method)
+	}
+	id, ok := params[0].(string)
+	if !ok {
+		return fmt.Errorf("filter id of %s is not a string", method)
+	}
+
+	m.filtersLock.Lock()
+	e, ok := m.filters[id]
+	m.filtersLock.Unlock()
+	if !ok {
+		return fmt.Errorf("filter %s not found", id)
+	}
+
+	err := m.callEndpoint(ctx, e, method, out, params...)
+	if method == "eth_uninstallFilter" && err == nil {
+		m.filtersLock.Lock()
+		delete(m.filters, id)
+		m.filtersLock.Unlock()
+	}
+	return err
+}
+
+// addFilter records the endpoint that created the filter
+// whose id is the output of the request
+func (m *Multi) addFilter(e *endpoint, out interface{}) {
+	data, err := json.Marshal(out)
+	if err != nil {
+		return
+	}
+	var id string
+	if err := json.Unmarshal(data, &id); err != nil {
+		return
+	}
+
+	m.filtersLock.Lock()
+	m.filters[id] = e
+	m.filtersLock.Unlock()
+}
+
+// CallBatch implements the BatchTransport interface. The requests
+// of filters are not supported in batches.
+func (m *Multi) CallBatch(ctx context.Context, elems []*BatchElem) error {
+	idempotent := true
+	for _, elem := range elems {
+		if !isIdempotent(elem.Method) {
+			idempotent = false
+		}
+		if isFilterMethod(elem.Method) {
+			return fmt.Errorf("method %s is not supported in batches of multiple endpoints", elem.Method)
+		}
+	}
+
+	var lastErr error
+	tried := map[*endpoint]struct{}{}
+	for {
+		e, err := m.selectEndpoint(tried)
+		if err != nil {
+			if lastErr != nil {
+				return lastErr
+			}
+			return err
+		}
+		tried[e] = struct{}{}
+
+		err = m.callBatchEndpoint(ctx, e, elems)
+		lastErr = err
+		if !isEndpointFailure(ctx, err) || !idempotent || len(tried) == len(m.endpoints) {
+			return err
+		}
+	}
+}
+
+func (m *Multi) callBatchEndpoint(ctx context.Context, e *endpoint, elems []*BatchElem) error {
+	t, err := e.getTransport(ctx)
+	if err != nil {
+		e.failure()
+		return err
+	}
+
+	batchTransport, ok := t.(BatchTransport)
+	if !ok {
+		for _, elem := range elems {
+			elem.Err = m.callEndpoint(ctx, e, elem.Method, elem.Out, elem.Params...)
+		}
+		return nil
+	}
+
+	now := time.Now()
+	err = batchTransport.CallBatch(ctx, elems)
+	if isEndpointFailure(ctx, err) {
+		e.failure()
+	} else {
+		e.success(time.Since(now))
+	}
+	return err
+}
+
+// nonIdempotentMethods are the methods that cannot be sent twice or
+// that depend on the state of a specific node
+var nonIdempotentMethods = map[string]struct{}{
+	"eth_sendTransaction":             {},
+	"eth_sendRawTransaction":          {},
+	"personal_sendTransaction":        {},
+	"eth_newFilter":                   {},
+	"eth_newBlockFilter":              {},
+	"eth_newPendingTransactionFilter": {},
+	"eth_getFilterChanges":            {},
+	"eth_getFilterLogs":               {},
+	"eth_uninstallFilter":             {},
+	"eth_subscribe":                   {},
+	"eth_unsubscribe":                 {},
+}
+
+func isIdempotent(method string) bool {
+	_, ok := nonIdempotentMethods[method]
+	return !ok
+}
+
+var (
+	// newFilterMethods create a filter in the node and return its id
+	newFilterMethods = map[string]struct{}{
+		"eth_newFilter":                   {},
+		"eth_newBlockFilter":              {},
+		"eth_newPendingTransactionFilter": {},
+	}
+
+	// filterMethods use the filter of the id in the first param
+	filterMethods = map[string]struct{}{
+		"eth_getFilterChanges": {},
+		"eth_getFilterLogs":    {},
+		"eth_uninstallFilter":  {},
+	}
+)
+
+func isFilterMethod(method string) bool {
+	if _, ok := newFilterMethods[method]; ok {
+		return true
+	}
+	_, ok := filterMethods[method]
+	return ok
+}
+
+// callContext makes a request with the context if the transport supports it
+func callContext(ctx context.Context, t Transport, method string, out interface{}, params ...interface{}) error {
+	if tt, ok := t.(ContextTransport); ok {
+		return tt.CallContext(ctx, method, out, params...)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- t.Call(method, out, params...)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,285 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mover-code/golang-web3/jsonrpc/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockNode struct {
+	*httptest.Server
+
+	block uint64
+	calls uint64
+}
+
+// newMockNode creates a node that replies to eth_blockNumber, to
+// eth_newFilter with its url and echoes the method of any other request
+func newMockNode(t *testing.T, block uint64) *mockNode {
+	n := &mockNode{block: block}
+	n.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		var req codec.Request
+		assert.NoError(t, json.Unmarshal(data, &req))
+
+		var result interface{}
+		if req.Method == "eth_blockNumber" {
+			result = fmt.Sprintf("0x%x", atomic.LoadUint64(&n.block))
+		} else if req.Method == "eth_newFilter" {
+			// the filter ids are unique across nodes
+			atomic.AddUint64(&n.calls, 1)
+			result = n.URL
+		} else {
+			atomic.AddUint64(&n.calls, 1)
+			result = req.Method
+		}
+		resp := codec.Response{ID: req.ID}
+		resp.Result, _ = json.Marshal(result)
+
+		data, err = json.Marshal(resp)
+		assert.NoError(t, err)
+		w.Write(data)
+	}))
+	return n
+}
+
+// deadAddr is an address where there is nothing listening
+const deadAddr = "http://127.0.0.1:1"
+
+func TestMulti_RoundRobin(t *testing.T) {
+	n0, n1 := newMockNode(t, 1), newMockNode(t, 1)
+	defer n0.Close()
+	defer n1.Close()
+
+	m, err := NewMulti([]string{n0.URL, n1.URL}, WithHealthCheckInterval(0))
+	assert.NoError(t, err)
+	defer m.Close()
+
+	for i := 0; i < 10; i++ {
+		var out string
+		assert.NoError(t, m.Call("a", &out))
+		assert.Equal(t, out, "a")
+	}
+	assert.Equal(t, n0.calls, uint64(5))
+	assert.Equal(t, n1.calls, uint64(5))
+}
+
+func TestMulti_Failover(t *testing.T) {
+	n0 := newMockNode(t, 1)
+	defer n0.Close()
+
+	m, err := NewMulti([]string{deadAddr, n0.URL}, WithHealthCheckInterval(0), WithMaxFailures(2))
+	assert.NoError(t, err)
+	defer m.Close()
+
+	// idempotent requests are retried in the other endpoint
+	for i := 0; i < 10; i++ {
+		var out string
+		assert.NoError(t, m.Call("eth_getBalance", &out))
+	}
+	assert.Equal(t, n0.calls, uint64(10))
+
+	// the endpoint is dead after the consecutive failures
+	assert.False(t, m.endpoints[0].isAlive(2))
+	assert.True(t, m.endpoints[1].isAlive(2))
+
+	// transactions are not retried, revive the dead endpoint
+	// so that the next request is sent to it
+	m.endpoints[0].failures = 0
+	m.seq = 1
+
+	var out string
+	assert.Error(t, m.Call("eth_sendRawTransaction", &out))
+}
+
+func TestMulti_HealthCheck(t *testing.T) {
+	n0 := newMockNode(t, 1)
+	defer n0.Close()
+
+	m, err := NewMulti([]string{deadAddr, n0.URL}, WithMaxFailures(1))
+	assert.NoError(t, err)
+	defer m.Close()
+
+	// the health check on creation marks the endpoint as dead
+	// and all the requests go to the alive node
+	assert.False(t, m.endpoints[0].isAlive(1))
+
+	for i := 0; i < 4; i++ {
+		var out string
+		assert.NoError(t, m.Call("eth_sendRawTransaction", &out))
+	}
+	assert.Equal(t, n0.calls, uint64(4))
+}
+
+func TestMulti_HighestBlock(t *testing.T) {
+	n0, n1, n2 := newMockNode(t, 100), newMockNode(t, 90), newMockNode(t, 99)
+	defer n0.Close()
+	defer n1.Close()
+	defer n2.Close()
+
+	m, err := NewMulti([]string{n0.URL, n1.URL, n2.URL}, WithHighestBlock(1))
+	assert.NoError(t, err)
+	defer m.Close()
+
+	for i := 0; i < 10; i++ {
+		var out string
+		assert.NoError(t, m.Call("a", &out))
+	}
+	assert.Equal(t, n0.calls+n2.calls, uint64(10))
+	assert.Equal(t, n1.calls, uint64(0))
+
+	// once the node catches up it gets requests again
+	atomic.StoreUint64(&n1.block, 100)
+	m.healthCheck()
+
+	for i := 0; i < 10; i++ {
+		var out string
+		assert.NoError(t, m.Call("a", &out))
+	}
+	assert.NotEqual(t, n1.calls, uint64(0))
+}
+
+func TestMulti_HighestBlockDeadEndpoint(t *testing.T) {
+	n0 := newMockNode(t, 100)
+	defer n0.Close()
+
+	m, err := NewMulti([]string{deadAddr, n0.URL}, WithHealthCheckInterval(0), WithHighestBlock(1), WithMaxFailures(3))
+	assert.NoError(t, err)
+	defer m.Close()
+
+	// the dead endpoint is ahead of the alive one
+	m.endpoints[0].block = 200
+	m.endpoints[0].failures = 3
+	m.endpoints[1].block = 100
+
+	var out string
+	assert.NoError(t, m.Call("a", &out))
+	assert.Equal(t, n0.calls, uint64(1))
+
+	// the endpoint in sync fails and the request is
+	// retried in the lagging endpoint
+	m.endpoints[0].failures = 0
+	m.endpoints[1].block = 10
+
+	assert.NoError(t, m.Call("a", &out))
+	assert.Equal(t, n0.calls, uint64(2))
+}
+
+func TestMulti_LowestLatency(t *testing.T) {
+	n0, n1 := newMockNode(t, 1), newMockNode(t, 1)
+	defer n0.Close()
+	defer n1.Close()
+
+	m, err := NewMulti([]string{n0.URL, n1.URL}, WithHealthCheckInterval(0), WithStrategy(LowestLatency))
+	assert.NoError(t, err)
+	defer m.Close()
+
+	// the endpoints without latency are selected first
+	m.endpoints[0].latency = 90
+	e, err := m.selectEndpoint(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, e, m.endpoints[1])
+
+	// the requests are weighted by the inverse of the latency
+	m.endpoints[1].latency = 10
+
+	num := 1000
+	selected := map[*endpoint]int{}
+	for i := 0; i < num; i++ {
+		e, err := m.selectEndpoint(nil)
+		assert.NoError(t, err)
+		selected[e]++
+	}
+	assert.Equal(t, selected[m.endpoints[0]]+selected[m.endpoints[1]], num)
+	assert.Greater(t, selected[m.endpoints[0]], 0)
+	assert.Greater(t, selected[m.endpoints[1]], 800)
+}
+
+func TestMulti_Close(t *testing.T) {
+	n0 := newMockNode(t, 1)
+	defer n0.Close()
+
+	m, err := NewMulti([]string{n0.URL}, WithHealthCheckInterval(time.Millisecond))
+	assert.NoError(t, err)
+
+	assert.NoError(t, m.Close())
+	assert.NoError(t, m.Close())
+}
+
+func TestMulti_Filters(t *testing.T) {
+	n0, n1 := newMockNode(t, 1), newMockNode(t, 1)
+	defer n0.Close()
+	defer n1.Close()
+
+	m, err := NewMulti([]string{n0.URL, n1.URL}, WithHealthCheckInterval(0))
+	assert.NoError(t, err)
+	defer m.Close()
+
+	// one filter in each node
+	var id0, id1 string
+	assert.NoError(t, m.Call("eth_newFilter", &id0))
+	assert.NoError(t, m.Call("eth_newFilter", &id1))
+	assert.NotEqual(t, id0, id1)
+
+	// the requests of the filter go to the node that created it
+	for i := 0; i < 4; i++ {
+		var out string
+		assert.NoError(t, m.Call("eth_getFilterChanges", &out, id0))
+	}
+	node := map[string]*mockNode{n0.URL: n0, n1.URL: n1}
+	assert.Equal(t, node[id0].calls, uint64(5))
+	assert.Equal(t, node[id1].calls, uint64(1))
+
+	var out string
+	assert.NoError(t, m.Call("eth_uninstallFilter", &out, id0))
+	assert.Error(t, m.Call("eth_getFilterChanges", &out, id0))
+
+	// filters created somewhere else are not known
+	assert.Error(t, m.Call("eth_getFilterLogs", &out, "0x1"))
+
+	// nor supported in batches
+	assert.Error(t, m.CallBatch(context.Background(), []*BatchElem{
+		{Method: "eth_getFilterChanges", Params: []interface{}{id1}, Out: &out},
+	}))
+}
+
+func TestMulti_DialTimeout(t *testing.T) {
+	// the node accepts the connections but never replies
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer lis.Close()
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	m, err := NewMulti([]string{"ws://" + lis.Addr().String()}, WithHealthCheckInterval(0))
+	assert.NoError(t, err)
+	defer m.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	now := time.Now()
+	var out string
+	assert.Error(t, m.CallContext(ctx, "eth_chainId", &out))
+	assert.Less(t, time.Since(now), time.Second)
+}
@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"context"
 	"os"
 	"strings"
 )
@@ -14,10 +15,50 @@ type Transport interface {
 	Close() error
 }
 
+// ContextTransport is a transport that can cancel the requests
+// with a context
+type ContextTransport interface {
+	// CallContext makes a jsonrpc request that is cancelled if the context is done
+	CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error
+}
+
 // PubSubTransport is a transport that allows subscriptions
 type PubSubTransport interface {
-	// Subscribe starts a subscription to a new event
-	Subscribe(method string, callback func(b []byte)) (func() error, error)
+	// Subscribe starts a subscription to a new event. The params
+	// are sent after the name of the subscription.
+	Subscribe(method string, callback func(b []byte), params ...interface{}) (func() error, error)
+}
+
+// ReconnectTransport is a transport that reconnects if the connection drops
+type ReconnectTransport interface {
+	// ConnectionEvents returns a channel to receive the disconnection and
+	// reconnection events. The subscriptions are created again on reconnection
+	// but the notifications sent while the connection was down are lost.
+	// The channel is closed by the returned function or when the transport
+	// is closed.
+	ConnectionEvents() (<-chan *ConnectionEvent, func())
+}
+
+// ConnectionEventType is the type of a connection event
+type ConnectionEventType int
+
+const (
+	// Disconnected happens when the connection drops
+	Disconnected ConnectionEventType = iota
+
+	// Reconnected happens when the connection is established again
+	// and the subscriptions have been created again
+	Reconnected
+)
+
+// ConnectionEvent is an event emitted when the connection
+// drops or is established again
+type ConnectionEvent struct {
+	Type ConnectionEventType
+
+	// Err is the error that dropped the connection on Disconnected
+	// events or the error to resubscribe on Reconnected events
+	Err error
 }
 
 const (
@@ -27,8 +68,14 @@ const (
 
 // NewTransport creates a new transport object
 func NewTransport(url string) (Transport, error) {
+	return newTransport(context.Background(), url)
+}
+
+// newTransport creates a new transport object whose
+// connection is dialed within the context
+func newTransport(ctx context.Context, url string) (Transport, error) {
 	if strings.HasPrefix(url, wsPrefix) || strings.HasPrefix(url, wssPrefix) {
-		t, err := newWebsocket(url)
+		t, err := newWebsocket(ctx, url)
 		if err != nil {
 			return nil, err
 		}
@@ -36,7 +83,7 @@ func NewTransport(url string) (Transport, error) {
 	}
 	if _, err := os.Stat(url); err == nil {
 		// path exists, it could be an ipc path
-		t, err := newIPC(url)
+		t, err := newIPC(ctx, url)
 		if err != nil {
 			return nil, err
 		}
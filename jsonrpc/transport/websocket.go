@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -13,20 +14,41 @@ import (
 	"github.com/gorilla/websocket"
 )
 
-func newWebsocket(url string) (Transport, error) {
-	wsConn, _, err := websocket.DefaultDialer.Dial(url, http.Header{})
+func newWebsocket(ctx context.Context, url string) (Transport, error) {
+	dial := func(ctx context.Context) (Codec, error) {
+		wsConn, _, err := websocket.DefaultDialer.DialContext(ctx, url, http.Header{})
+		if err != nil {
+			return nil, err
+		}
+		codec := &websocketCodec{
+			conn: wsConn,
+		}
+		return codec, nil
+	}
+	codec, err := dial(ctx)
 	if err != nil {
 		return nil, err
 	}
-	codec := &websocketCodec{
-		conn: wsConn,
-	}
-	return newStream(codec)
+	return newStream(codec, func() (Codec, error) {
+		return dial(context.Background())
+	})
 }
 
-// ErrTimeout happens when the websocket requests times out
+// ErrTimeout happens when the websocket requests without
+// a context times out
 var ErrTimeout = fmt.Errorf("timeout")
 
+// ErrConnectionClosed happens when the connection drops before the response arrives
+var ErrConnectionClosed = fmt.Errorf("connection closed")
+
+const (
+	// defaultCallTimeout is the timeout of the requests without a context
+	defaultCallTimeout = 5 * time.Second
+
+	minReconnectBackoff = 100 * time.Millisecond
+	maxReconnectBackoff = 30 * time.Second
+)
+
 type ackMessage struct {
 	buf []byte
 	err error
@@ -34,9 +56,58 @@ type ackMessage struct {
 
 type callback func(b []byte, err error)
 
+type subscription struct {
+	id       string
+	method   string
+	params   []interface{}
+	callback func(b []byte)
+
+	// the notifications are queued and delivered in order
+	// by a single goroutine while the queue is not empty
+	lock    sync.Mutex
+	queue   [][]byte
+	running bool
+}
+
+// deliver queues the notification for the callback
+func (s *subscription) deliver(b []byte) {
+	s.lock.Lock()
+	s.queue = append(s.queue, b)
+	if s.running {
+		s.lock.Unlock()
+		return
+	}
+	s.running = true
+	s.lock.Unlock()
+
+	go s.run()
+}
+
+func (s *subscription) run() {
+	for {
+		s.lock.Lock()
+		if len(s.queue) == 0 {
+			s.running = false
+			s.lock.Unlock()
+			return
+		}
+		b := s.queue[0]
+		s.queue = s.queue[1:]
+		s.lock.Unlock()
+
+		s.callback(b)
+	}
+}
+
 type stream struct {
-	seq   uint64
-	codec Codec
+	seq uint64
+
+	codecLock sync.Mutex
+	codec     Codec
+
+	// dial opens a new connection, if set the stream
+	// reconnects when the connection drops
+	dial func() (Codec, error)
 
 	// call handlers
 	handlerLock sync.Mutex
@@ -44,27 +115,50 @@ type stream struct {
 
 	// subscriptions
 	subsLock sync.Mutex
-	subs     map[string]func(b []byte)
+	subs     map[string]*subscription
+
+	// notifications are the subscription notifications in the order they
+	// are received, waiting to be dispatched to their subscription
+	notificationsLock sync.Mutex
+	notifications     []codec.Request
+	notificationsCh   chan struct{}
+
+	// connection events
+	eventsLock sync.Mutex
+	events     []chan *ConnectionEvent
 
 	closeCh chan struct{}
-	timer   *time.Timer
 }
 
-func newStream(codec Codec) (*stream, error) {
+func newStream(codec Codec, dial func() (Codec, error)) (*stream, error) {
 	w := &stream{
 		codec:   codec,
+		dial:    dial,
 		closeCh: make(chan struct{}),
 		handler: map[uint64]callback{},
-		subs:    map[string]func(b []byte){},
+		subs:    map[string]*subscription{},
+
+		notificationsCh: make(chan struct{}, 1),
 	}
 
 	go w.listen()
+	go w.dispatchNotifications()
 	return w, nil
 }
 
 // Close implements the the transport interface
 func (s *stream) Close() error {
 	close(s.closeCh)
+
+	s.eventsLock.Lock()
+	for _, ch := range s.events {
+		close(ch)
+	}
+	s.events = nil
+	s.eventsLock.Unlock()
+
+	s.codecLock.Lock()
+	defer s.codecLock.Unlock()
 	return s.codec.Close()
 }
 
@@ -81,22 +175,91 @@ func (s *stream) isClosed() bool {
 	}
 }
 
+func (s *stream) getCodec() Codec {
+	s.codecLock.Lock()
+	defer s.codecLock.Unlock()
+	return s.codec
+}
+
+// write sends a message, the writes are serialized
+// since the connections do not support concurrent writers
+func (s *stream) write(b []byte) error {
+	s.codecLock.Lock()
+	defer s.codecLock.Unlock()
+	return s.codec.Write(b)
+}
+
+// ConnectionEvents implements the ReconnectTransport interface
+func (s *stream) ConnectionEvents() (<-chan *ConnectionEvent, func()) {
+	s.eventsLock.Lock()
+	defer s.eventsLock.Unlock()
+
+	ch := make(chan *ConnectionEvent, 10)
+	if s.isClosed() {
+		close(ch)
+		return ch, func() {}
+	}
+	s.events = append(s.events, ch)
+
+	unsubscribe := func() {
+		s.eventsLock.Lock()
+		defer s.eventsLock.Unlock()
+
+		for indx, c := range s.events {
+			if c == ch {
+				s.events = append(s.events[:indx], s.events[indx+1:]...)
+				close(ch)
+				return
+			}
+		}
+	}
+	return ch, unsubscribe
+}
+
+func (s *stream) emitEvent(evnt *ConnectionEvent) {
+	s.eventsLock.Lock()
+	defer s.eventsLock.Unlock()
+
+	for _, ch := range s.events {
+		select {
+		case ch <- evnt:
+		default:
+		}
+	}
+}
+
 func (s *stream) listen() {
 	buf := []byte{}
 
 	for {
 		var err error
-		buf, err = s.codec.Read(buf[:0])
+		buf, err = s.getCodec().Read(buf[:0])
 		if err != nil {
-			if !s.isClosed() {
-				// log error
+			if s.isClosed() || s.dial == nil {
+				return
 			}
-			return
+			if !s.reconnect(err) {
+				return
+			}
+			continue
+		}
+
+		// the frames that cannot be decoded are skipped
+		if len(buf) != 0 && buf[0] == '[' {
+			// batch response
+			var resps []codec.Response
+			if err = json.Unmarshal(buf, &resps); err != nil {
+				continue
+			}
+			for _, resp := range resps {
+				go s.handleMsg(resp)
+			}
+			continue
 		}
 
 		var resp codec.Response
 		if err = json.Unmarshal(buf, &resp); err != nil {
-			return
+			continue
 		}
 
 		if resp.ID != 0 {
@@ -105,12 +268,122 @@ func (s *stream) listen() {
 			// handle subscription
 			var respSub codec.Request
 			if err = json.Unmarshal(buf, &respSub); err != nil {
-				return
+				continue
 			}
 
 			if respSub.Method == "eth_subscription" {
-				go s.handleSubscription(respSub)
+				s.queueNotification(respSub)
+			}
+		}
+	}
+}
+
+// reconnect dials the connection again with an exponential backoff. It returns
+// false if the stream is closed before the connection is established.
+func (s *stream) reconnect(err error) bool {
+	s.getCodec().Close()
+	s.emitEvent(&ConnectionEvent{Type: Disconnected, Err: err})
+
+	// the responses of the pending requests are lost
+	s.handlerLock.Lock()
+	handlers := s.handler
+	s.handler = map[uint64]callback{}
+	s.handlerLock.Unlock()
+
+	for _, callback := range handlers {
+		callback(nil, ErrConnectionClosed)
+	}
+
+	backoff := minReconnectBackoff
+	for {
+		select {
+		case <-time.After(backoff):
+		case <-s.closeCh:
+			return false
+		}
+
+		codec, err := s.dial()
+		if err == nil {
+			s.codecLock.Lock()
+			if s.isClosed() {
+				s.codecLock.Unlock()
+				codec.Close()
+				return false
 			}
+			s.codec = codec
+			s.codecLock.Unlock()
+			break
+		}
+
+		if backoff *= 2; backoff > maxReconnectBackoff {
+			backoff = maxReconnectBackoff
+		}
+	}
+
+	// the listen loop has to be running to receive the responses
+	go func() {
+		err := s.resubscribe()
+		s.emitEvent(&ConnectionEvent{Type: Reconnected, Err: err})
+	}()
+	return true
+}
+
+// resubscribe creates again the subscriptions in the new connection
+// and remaps them with the new ids
+func (s *stream) resubscribe() error {
+	s.subsLock.Lock()
+	defer s.subsLock.Unlock()
+
+	var lastErr error
+
+	subs := s.subs
+	s.subs = map[string]*subscription{}
+	for _, sub := range subs {
+		params := append([]interface{}{sub.method}, sub.params...)
+
+		var id string
+		if err := s.Call("eth_subscribe", &id, params...); err != nil {
+			// keep the old id to try again on the next reconnection
+			lastErr = err
+			s.subs[sub.id] = sub
+			continue
+		}
+		sub.id = id
+		s.subs[id] = sub
+	}
+	return lastErr
+}
+
+// queueNotification queues the notification without blocking the listen
+// loop, which has to keep reading the responses of eth_subscribe
+func (s *stream) queueNotification(notification codec.Request) {
+	s.notificationsLock.Lock()
+	s.notifications = append(s.notifications, notification)
+	s.notificationsLock.Unlock()
+
+	select {
+	case s.notificationsCh <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchNotifications hands the notifications in order to their
+// subscriptions, each subscription runs its callback in order too
+func (s *stream) dispatchNotifications() {
+	for {
+		select {
+		case <-s.notificationsCh:
+		case <-s.closeCh:
+			return
+		}
+
+		s.notificationsLock.Lock()
+		notifications := s.notifications
+		s.notifications = nil
+		s.notificationsLock.Unlock()
+
+		for _, notification := range notifications {
+			s.handleSubscription(notification)
 		}
 	}
 }
@@ -122,15 +395,14 @@ func (s *stream) handleSubscription(response codec.Request) {
 	}
 
 	s.subsLock.Lock()
-	callback, ok := s.subs[sub.ID]
+	subscription, ok := s.subs[sub.ID]
 	s.subsLock.Unlock()
 
 	if !ok {
 		return
 	}
 
-	// call the callback function
-	callback(sub.Result)
+	subscription.deliver(sub.Result)
 }
 
 func (s *stream) handleMsg(response codec.Response) {
@@ -163,21 +435,33 @@ func (s *stream) setHandler(id uint64, ack chan *ackMessage) {
 	s.handlerLock.Lock()
 	s.handler[id] = callback
 	s.handlerLock.Unlock()
+}
 
-	s.timer = time.AfterFunc(5*time.Second, func() {
-		s.handlerLock.Lock()
-		delete(s.handler, id)
-		s.handlerLock.Unlock()
-
-		select {
-		case ack <- &ackMessage{nil, ErrTimeout}:
-		default:
-		}
-	})
+func (s *stream) removeHandler(id uint64) {
+	s.handlerLock.Lock()
+	delete(s.handler, id)
+	s.handlerLock.Unlock()
 }
 
-// Call implements the transport interface
+// Call implements the transport interface. The request
+// fails with ErrTimeout if there is no response in time.
 func (s *stream) Call(method string, out interface{}, params ...interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCallTimeout)
+	defer cancel()
+
+	err := s.CallContext(ctx, method, out, params...)
+	if err == context.DeadlineExceeded {
+		return ErrTimeout
+	}
+	return err
+}
+
+// CallContext implements the ContextTransport interface
+func (s *stream) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	seq := s.incSeq()
 	request := codec.Request{
 		ID:     seq,
@@ -191,18 +475,26 @@ func (s *stream) Call(method string, out interface{}, params ...interface{}) err
 		request.Params = data
 	}
 
-	ack := make(chan *ackMessage)
+	ack := make(chan *ackMessage, 1)
 	s.setHandler(seq, ack)
 
 	raw, err := json.Marshal(request)
 	if err != nil {
+		s.removeHandler(seq)
 		return err
 	}
-	if err := s.codec.Write(raw); err != nil {
+	if err := s.write(raw); err != nil {
+		s.removeHandler(seq)
 		return err
 	}
 
-	resp := <-ack
+	var resp *ackMessage
+	select {
+	case resp = <-ack:
+	case <-ctx.Done():
+		s.removeHandler(seq)
+		return ctx.Err()
+	}
 	if resp.err != nil {
 		return resp.err
 	}
@@ -212,17 +504,63 @@ func (s *stream) Call(method string, out interface{}, params ...interface{}) err
 	return nil
 }
 
-func (s *stream) unsubscribe(id string) error {
+// CallBatch implements the BatchTransport interface
+func (s *stream) CallBatch(ctx context.Context, elems []*BatchElem) error {
+	if len(elems) == 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// reserve a range of consecutive ids for the batch
+	num := uint64(len(elems))
+	seq := atomic.AddUint64(&s.seq, num) - num + 1
+
+	acks := make([]chan *ackMessage, len(elems))
+	for indx := range elems {
+		acks[indx] = make(chan *ackMessage, 1)
+		s.setHandler(seq+uint64(indx), acks[indx])
+	}
+	removeHandlers := func() {
+		for indx := range elems {
+			s.removeHandler(seq + uint64(indx))
+		}
+	}
+
+	raw, err := encodeBatch(seq, elems)
+	if err != nil {
+		removeHandlers()
+		return err
+	}
+	if err := s.write(raw); err != nil {
+		removeHandlers()
+		return err
+	}
+
+	for indx, elem := range elems {
+		select {
+		case resp := <-acks[indx]:
+			elem.setResult(resp.buf, resp.err)
+		case <-ctx.Done():
+			removeHandlers()
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (s *stream) unsubscribe(sub *subscription) error {
 	s.subsLock.Lock()
 	defer s.subsLock.Unlock()
 
-	if _, ok := s.subs[id]; !ok {
-		return fmt.Errorf("subscription %s not found", id)
+	if _, ok := s.subs[sub.id]; !ok {
+		return fmt.Errorf("subscription %s not found", sub.id)
 	}
-	delete(s.subs, id)
+	delete(s.subs, sub.id)
 
 	var result bool
-	if err := s.Call("eth_unsubscribe", &result, id); err != nil {
+	if err := s.Call("eth_unsubscribe", &result, sub.id); err != nil {
 		return err
 	}
 	if !result {
@@ -231,23 +569,27 @@ func (s *stream) unsubscribe(id string) error {
 	return nil
 }
 
-func (s *stream) setSubscription(id string, callback func(b []byte)) {
+// Subscribe implements the PubSubTransport interface
+func (s *stream) Subscribe(method string, callback func(b []byte), params ...interface{}) (func() error, error) {
+	// hold the lock until the subscription is set to not
+	// lose the notifications sent right after the response
 	s.subsLock.Lock()
 	defer s.subsLock.Unlock()
 
-	s.subs[id] = callback
-}
-
-// Subscribe implements the PubSubTransport interface
-func (s *stream) Subscribe(method string, callback func(b []byte)) (func() error, error) {
 	var out string
-	if err := s.Call("eth_subscribe", &out, method); err != nil {
+	if err := s.Call("eth_subscribe", &out, append([]interface{}{method}, params...)...); err != nil {
 		return nil, err
 	}
 
-	s.setSubscription(out, callback)
+	sub := &subscription{
+		id:       out,
+		method:   method,
+		params:   params,
+		callback: callback,
+	}
+	s.subs[sub.id] = sub
 	cancel := func() error {
-		return s.unsubscribe(out)
+		return s.unsubscribe(sub)
 	}
 	return cancel, nil
 }
@@ -0,0 +1,279 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/mover-code/golang-web3/jsonrpc/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockWSServer struct {
+	*httptest.Server
+
+	lock  sync.Mutex
+	conns []*websocket.Conn
+	subID int
+}
+
+// newMockWSServer creates a websocket server that replies to eth_subscribe
+// with a new id and sends a notification with the connection number.
+// eth_echo is answered with its method after an invalid frame and the
+// other requests are not answered
+func newMockWSServer(t *testing.T) *mockWSServer {
+	m := &mockWSServer{}
+	upgrader := websocket.Upgrader{}
+
+	m.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		m.lock.Lock()
+		m.conns = append(m.conns, conn)
+		connNum := len(m.conns)
+		m.lock.Unlock()
+
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if data[0] == '[' {
+				m.writeBatch(t, conn, data)
+				continue
+			}
+			var req codec.Request
+			assert.NoError(t, json.Unmarshal(data, &req))
+
+			if req.Method == "eth_echo" {
+				// an invalid frame is sent before the response
+				conn.WriteMessage(websocket.TextMessage, []byte("{invalid"))
+
+				resp := codec.Response{ID: req.ID}
+				resp.Result, _ = json.Marshal(req.Method)
+				data, _ = json.Marshal(resp)
+				conn.WriteMessage(websocket.TextMessage, data)
+				continue
+			}
+			if req.Method != "eth_subscribe" {
+				continue
+			}
+
+			m.lock.Lock()
+			m.subID++
+			id := fmt.Sprintf("0x%d", m.subID)
+			m.lock.Unlock()
+
+			resp := codec.Response{ID: req.ID}
+			resp.Result, _ = json.Marshal(id)
+			data, _ = json.Marshal(resp)
+			conn.WriteMessage(websocket.TextMessage, data)
+
+			notification := fmt.Sprintf(`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"%s","result":%d}}`, id, connNum)
+			conn.WriteMessage(websocket.TextMessage, []byte(notification))
+		}
+	}))
+	return m
+}
+
+// writeBatch replies to a batch in reverse order with the method
+// of each request as result or an error for eth_fail
+func (m *mockWSServer) writeBatch(t *testing.T, conn *websocket.Conn, data []byte) {
+	var reqs []codec.Request
+	assert.NoError(t, json.Unmarshal(data, &reqs))
+
+	resps := []codec.Response{}
+	for i := len(reqs) - 1; i >= 0; i-- {
+		resp := codec.Response{ID: reqs[i].ID}
+		if reqs[i].Method == "eth_fail" {
+			resp.Error = &codec.ErrorObject{Code: -32000, Message: "failed"}
+		} else {
+			resp.Result, _ = json.Marshal(reqs[i].Method)
+		}
+		resps = append(resps, resp)
+	}
+	data, _ = json.Marshal(resps)
+	conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func (m *mockWSServer) dropConnections() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, conn := range m.conns {
+		conn.Close()
+	}
+}
+
+func TestWebsocket_Reconnect(t *testing.T) {
+	srv := newMockWSServer(t)
+	defer srv.Close()
+
+	tt, err := NewTransport("ws://" + strings.TrimPrefix(srv.URL, "http://"))
+	assert.NoError(t, err)
+	defer tt.Close()
+
+	events, _ := tt.(ReconnectTransport).ConnectionEvents()
+
+	dataCh := make(chan string, 10)
+	_, err = tt.(PubSubTransport).Subscribe("newHeads", func(b []byte) {
+		dataCh <- string(b)
+	})
+	assert.NoError(t, err)
+
+	recv := func() string {
+		select {
+		case data := <-dataCh:
+			return data
+		case <-time.After(5 * time.Second):
+			t.Fatal("notification not received")
+		}
+		return ""
+	}
+	recvEvent := func() *ConnectionEvent {
+		select {
+		case evnt := <-events:
+			return evnt
+		case <-time.After(5 * time.Second):
+			t.Fatal("connection event not received")
+		}
+		return nil
+	}
+
+	assert.Equal(t, recv(), "1")
+
+	srv.dropConnections()
+
+	assert.Equal(t, recvEvent().Type, Disconnected)
+
+	evnt := recvEvent()
+	assert.Equal(t, evnt.Type, Reconnected)
+	assert.NoError(t, evnt.Err)
+
+	// the subscription is created again with a new id
+	assert.Equal(t, recv(), "2")
+
+	s := tt.(*stream)
+	s.subsLock.Lock()
+	_, ok := s.subs["0x2"]
+	s.subsLock.Unlock()
+	assert.True(t, ok)
+}
+
+func TestWebsocket_CallBatch(t *testing.T) {
+	srv := newMockWSServer(t)
+	defer srv.Close()
+
+	tt, err := NewTransport("ws://" + strings.TrimPrefix(srv.URL, "http://"))
+	assert.NoError(t, err)
+	defer tt.Close()
+
+	var res0, res2 string
+	elems := []*BatchElem{
+		{Method: "eth_blockNumber", Out: &res0},
+		{Method: "eth_fail", Params: []interface{}{"0x1"}},
+		{Method: "eth_chainId", Out: &res2},
+	}
+	assert.NoError(t, tt.(BatchTransport).CallBatch(context.Background(), elems))
+
+	// the responses are matched by id and not by position
+	assert.NoError(t, elems[0].Err)
+	assert.Equal(t, res0, "eth_blockNumber")
+	assert.NoError(t, elems[2].Err)
+	assert.Equal(t, res2, "eth_chainId")
+
+	// a failed request does not fail the whole batch
+	obj, ok := elems[1].Err.(*codec.ErrorObject)
+	assert.True(t, ok)
+	assert.Equal(t, obj.Message, "failed")
+
+	// empty batches are not sent
+	assert.NoError(t, tt.(BatchTransport).CallBatch(context.Background(), nil))
+}
+
+func TestWebsocket_ConnectionEventsClose(t *testing.T) {
+	srv := newMockWSServer(t)
+	defer srv.Close()
+
+	tt, err := NewTransport("ws://" + strings.TrimPrefix(srv.URL, "http://"))
+	assert.NoError(t, err)
+
+	s := tt.(*stream)
+	events0, unsubscribe0 := s.ConnectionEvents()
+	events1, _ := s.ConnectionEvents()
+
+	// the channel is closed and removed when unsubscribed
+	unsubscribe0()
+	unsubscribe0()
+	_, ok := <-events0
+	assert.False(t, ok)
+	assert.Len(t, s.events, 1)
+
+	// the remaining channels are closed with the transport
+	assert.NoError(t, tt.Close())
+	_, ok = <-events1
+	assert.False(t, ok)
+	assert.Len(t, s.events, 0)
+
+	events2, _ := s.ConnectionEvents()
+	_, ok = <-events2
+	assert.False(t, ok)
+}
+
+func TestWebsocket_SkipInvalidFrames(t *testing.T) {
+	srv := newMockWSServer(t)
+	defer srv.Close()
+
+	tt, err := NewTransport("ws://" + strings.TrimPrefix(srv.URL, "http://"))
+	assert.NoError(t, err)
+	defer tt.Close()
+
+	for i := 0; i < 2; i++ {
+		var res string
+		assert.NoError(t, tt.Call("eth_echo", &res))
+		assert.Equal(t, res, "eth_echo")
+	}
+}
+
+func TestWebsocket_CallContextDeadline(t *testing.T) {
+	srv := newMockWSServer(t)
+	defer srv.Close()
+
+	tt, err := NewTransport("ws://" + strings.TrimPrefix(srv.URL, "http://"))
+	assert.NoError(t, err)
+	defer tt.Close()
+
+	s := tt.(*stream)
+
+	// the request waits for the context and not for a fixed timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	var res string
+	assert.Equal(t, s.CallContext(ctx, "eth_blockNumber", &res), context.DeadlineExceeded)
+
+	s.handlerLock.Lock()
+	assert.Len(t, s.handler, 0)
+	s.handlerLock.Unlock()
+
+	// a batch within the deadline of the context is not timed out
+	var res0 string
+	elems := []*BatchElem{
+		{Method: "eth_blockNumber", Out: &res0},
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	assert.NoError(t, s.CallBatch(ctx, elems))
+	assert.NoError(t, elems[0].Err)
+	assert.Equal(t, res0, "eth_blockNumber")
+}
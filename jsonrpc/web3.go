@@ -1,8 +1,11 @@
 package jsonrpc
 
+import "context"
+
 // Web3 is the web3 namespace
 type Web3 struct {
-	c *Client
+	c   *Client
+	ctx context.Context
 }
 
 // Web3 returns the reference to the web3 namespace
@@ -10,17 +13,27 @@ func (c *Client) Web3() *Web3 {
 	return c.endpoints.w
 }
 
+// WithContext returns a copy of the web3 namespace that sends
+// the requests with the given context
+func (w *Web3) WithContext(ctx context.Context) *Web3 {
+	return &Web3{c: w.c, ctx: ctx}
+}
+
+func (w *Web3) call(method string, out interface{}, params ...interface{}) error {
+	return w.c.CallContext(contextOrBackground(w.ctx), method, out, params...)
+}
+
 // ClientVersion returns the current client version
 func (w *Web3) ClientVersion() (string, error) {
 	var out string
-	err := w.c.Call("web3_clientVersion", &out)
+	err := w.call("web3_clientVersion", &out)
 	return out, err
 }
 
 // Sha3 returns Keccak-256 (not the standardized SHA3-256) of the given data
 func (w *Web3) Sha3(val []byte) ([]byte, error) {
 	var out string
-	if err := w.c.Call("web3_sha3", &out, encodeToHex(val)); err != nil {
+	if err := w.call("web3_sha3", &out, encodeToHex(val)); err != nil {
 		return nil, err
 	}
 	return parseHexBytes(out)
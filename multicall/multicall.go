@@ -0,0 +1,289 @@
+package multicall
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	web3 "github.com/mover-code/golang-web3"
+	"github.com/mover-code/golang-web3/abi"
+	"github.com/mover-code/golang-web3/contract"
+	"github.com/mover-code/golang-web3/jsonrpc"
+	"github.com/mover-code/golang-web3/jsonrpc/codec"
+)
+
+// DefaultAddress is the address of the Multicall3 contract, which
+// is the same in most of the chains
+var DefaultAddress = web3.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+const (
+	defaultMaxCalldataSize = 100 * 1024
+
+	// inputOverhead is the size of the encoded input of aggregate3
+	// besides the calls: the selector and the offset and length of
+	// the array of calls
+	inputOverhead = 4 + 2*32
+
+	// callOverhead is the size of a call in the encoded input of
+	// aggregate3 besides the calldata: the offset of the call, the
+	// target, allowFailure and the offset and length of the calldata
+	callOverhead = 5 * 32
+)
+
+var aggregate3 = abi.MustNewMethod("function aggregate3(tuple(address target, bool allowFailure, bytes callData)[] calls) payable returns (tuple(bool success, bytes returnData)[] returnData)")
+
+type call3 struct {
+	Target       web3.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type result3 struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// Config is the configuration of the multicall
+type Config struct {
+	// Address is the address of the Multicall3 contract
+	Address web3.Address
+
+	// MaxCalldataSize is the maximum size of the calldata of each
+	// aggregate3 call, the calls are split in several requests if
+	// they do not fit
+	MaxCalldataSize int
+}
+
+type ConfigOption func(*Config)
+
+func WithAddress(addr web3.Address) ConfigOption {
+	return func(c *Config) {
+		c.Address = addr
+	}
+}
+
+func WithMaxCalldataSize(size int) ConfigOption {
+	return func(c *Config) {
+		c.MaxCalldataSize = size
+	}
+}
+
+// DefaultConfig returns the default multicall config
+func DefaultConfig() *Config {
+	return &Config{
+		Address:         DefaultAddress,
+		MaxCalldataSize: defaultMaxCalldataSize,
+	}
+}
+
+// Call is a method call of a contract in a multicall
+type Call struct {
+	contract       *contract.Contract
+	method         *abi.Method
+	data           []byte
+	err            error
+	requireSuccess bool
+}
+
+// NewCall creates a call of the method of the contract. As in
+// contract.Call, the method is either the name or the signature.
+func NewCall(c *contract.Contract, method string, args ...interface{}) *Call {
+	call := &Call{
+		contract: c,
+	}
+	call.method, call.err = c.ABI().FindMethod(method, args...)
+	if call.err != nil {
+		return call
+	}
+	data, err := abi.Encode(args, call.method.Inputs)
+	if err != nil {
+		call.err = fmt.Errorf("failed to encode arguments: %v", err)
+		return call
+	}
+	call.data = append(call.method.ID(), data...)
+	return call
+}
+
+// RequireSuccess fails all the calls sent in the same aggregate3
+// request if this call fails. The whole request reverts, so the calls
+// of the request fail with the error of the node and the calls sent in
+// other requests are not affected.
+func (c *Call) RequireSuccess() *Call {
+	c.requireSuccess = true
+	return c
+}
+
+// Result is the outcome of a call in a multicall
+type Result struct {
+	// Success is set if the call did not revert and
+	// its output was decoded
+	Success bool
+
+	// Output is the decoded output of the method
+	Output map[string]interface{}
+
+	// ReturnData is the raw output or revert data of the call
+	ReturnData []byte
+
+	// Err is the reason of the failure of the call, which is an
+	// abi.RevertError if the call reverted
+	Err error
+}
+
+// Multicall packs many contract calls in a single eth_call
+// with the aggregate3 method of the Multicall3 contract
+type Multicall struct {
+	provider *jsonrpc.Client
+	config   *Config
+}
+
+// NewMulticall creates a new multicall
+func NewMulticall(provider *jsonrpc.Client, opts ...ConfigOption) *Multicall {
+	config := DefaultConfig()
+	for _, opt := range opts {
+		opt(config)
+	}
+	return &Multicall{
+		provider: provider,
+		config:   config,
+	}
+}
+
+// Call executes the calls at the given block and returns their
+// results in the same order
+func (m *Multicall) Call(block web3.BlockNumber, calls ...*Call) ([]*Result, error) {
+	return m.CallContext(context.Background(), block, calls...)
+}
+
+// CallContext executes the calls and returns once the context is done. The
+// calls are split in as many aggregate3 requests as required to keep the
+// calldata below the limit. The calls that cannot be encoded fail without
+// being sent.
+func (m *Multicall) CallContext(ctx context.Context, block web3.BlockNumber, calls ...*Call) ([]*Result, error) {
+	results := make([]*Result, len(calls))
+
+	var batch []int
+	size := inputOverhead
+	for indx, call := range calls {
+		if call.err != nil {
+			results[indx] = &Result{Err: call.err}
+			continue
+		}
+		callSize := callOverhead + (len(call.data)+31)/32*32
+		if len(batch) != 0 && size+callSize > m.config.MaxCalldataSize {
+			if err := m.aggregate(ctx, block, calls, batch, results); err != nil {
+				return nil, err
+			}
+			batch, size = nil, inputOverhead
+		}
+		batch = append(batch, indx)
+		size += callSize
+	}
+	if len(batch) != 0 {
+		if err := m.aggregate(ctx, block, calls, batch, results); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// aggregate sends the calls in the batch with aggregate3
+// and sets their results
+func (m *Multicall) aggregate(ctx context.Context, block web3.BlockNumber, calls []*Call, batch []int, results []*Result) error {
+	input := make([]*call3, 0, len(batch))
+	for _, indx := range batch {
+		call := calls[indx]
+		input = append(input, &call3{
+			Target:       call.contract.Addr(),
+			AllowFailure: !call.requireSuccess,
+			CallData:     call.data,
+		})
+	}
+	data, err := abi.Encode([]interface{}{input}, aggregate3.Inputs)
+	if err != nil {
+		return err
+	}
+
+	msg := &web3.CallMsg{
+		To:   &m.config.Address,
+		Data: append(aggregate3.ID(), data...),
+	}
+	rawStr, err := m.provider.Eth().WithContext(ctx).Call(msg, block)
+	if err != nil {
+		var obj *codec.ErrorObject
+		if !requireSuccess(calls, batch) || !errors.As(err, &obj) {
+			return err
+		}
+		// a required call of the batch failed
+		for _, indx := range batch {
+			results[indx] = &Result{Err: err}
+		}
+		return nil
+	}
+	if !strings.HasPrefix(rawStr, "0x") {
+		return fmt.Errorf("invalid response %q", rawStr)
+	}
+	raw, err := hex.DecodeString(rawStr[2:])
+	if err != nil {
+		return err
+	}
+	if len(raw) == 0 {
+		return fmt.Errorf("empty response, is there a multicall contract at %s?", m.config.Address)
+	}
+
+	var output struct {
+		ReturnData []*result3
+	}
+	if err := abi.DecodeStruct(aggregate3.Outputs, raw, &output); err != nil {
+		return err
+	}
+	if len(output.ReturnData) != len(batch) {
+		return fmt.Errorf("expected %d results but found %d", len(batch), len(output.ReturnData))
+	}
+
+	for i, indx := range batch {
+		results[indx] = decodeResult(calls[indx], output.ReturnData[i])
+	}
+	return nil
+}
+
+// requireSuccess returns whether any of the calls of the batch is required
+func requireSuccess(calls []*Call, batch []int) bool {
+	for _, indx := range batch {
+		if calls[indx].requireSuccess {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeResult(call *Call, res *result3) *Result {
+	result := &Result{
+		ReturnData: res.ReturnData,
+	}
+	if !res.Success {
+		revertErr, err := abi.DecodeRevert(call.contract.ABI(), res.ReturnData)
+		if err != nil {
+			result.Err = fmt.Errorf("execution reverted: %v", err)
+		} else {
+			result.Err = revertErr
+		}
+		return result
+	}
+	if len(res.ReturnData) == 0 && len(call.method.Outputs.TupleElems()) != 0 {
+		// the target is not a contract
+		result.Err = fmt.Errorf("empty response")
+		return result
+	}
+
+	output, err := call.method.DecodeOutput(res.ReturnData)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Success = true
+	result.Output = output
+	return result
+}
@@ -0,0 +1,254 @@
+package multicall
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	web3 "github.com/mover-code/golang-web3"
+	"github.com/mover-code/golang-web3/abi"
+	"github.com/mover-code/golang-web3/contract"
+	"github.com/mover-code/golang-web3/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	tokenAbi = abi.MustNewABI(`[
+		{"type": "function", "name": "balanceOf", "stateMutability": "view",
+			"inputs": [{"name": "owner", "type": "address"}],
+			"outputs": [{"name": "balance", "type": "uint256"}]},
+		{"type": "error", "name": "Blocked", "inputs": [{"name": "owner", "type": "address"}]}
+	]`)
+
+	tokenAddr   = web3.Address{0x10}
+	blockedAddr = web3.Address{0xff}
+	emptyAddr   = web3.Address{0x20}
+)
+
+// executeToken executes a call of the mock token whose balances are the
+// first byte of the address of the owner and that reverts for blockedAddr
+func executeToken(t *testing.T, target web3.Address, data []byte) (bool, []byte) {
+	if target != tokenAddr {
+		// not a contract
+		return true, []byte{}
+	}
+
+	method := tokenAbi.Methods["balanceOf"]
+	assert.Equal(t, data[:4], method.ID())
+
+	args, err := method.DecodeInput(data)
+	assert.NoError(t, err)
+	owner := args["owner"].(web3.Address)
+
+	if owner == blockedAddr {
+		blocked := tokenAbi.Errors["Blocked"]
+		res, err := abi.Encode([]interface{}{owner}, blocked.Inputs)
+		assert.NoError(t, err)
+		return false, append(blocked.ID(), res...)
+	}
+
+	res, err := abi.Encode([]interface{}{big.NewInt(int64(owner[0]))}, method.Outputs)
+	assert.NoError(t, err)
+	return true, res
+}
+
+// newMulticallServer is a node with the Multicall3 contract that
+// records the size of the calldata of each eth_call request
+func newMulticallServer(t *testing.T, requests *[]int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string
+			Params []interface{}
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, req.Method, "eth_call")
+		msg := req.Params[0].(map[string]interface{})
+		assert.Equal(t, msg["to"], DefaultAddress.String())
+
+		data, err := hex.DecodeString(msg["data"].(string)[2:])
+		assert.NoError(t, err)
+		*requests = append(*requests, len(data))
+		assert.Equal(t, data[:4], aggregate3.ID())
+
+		var input struct {
+			Calls []*call3
+		}
+		assert.NoError(t, abi.DecodeStruct(aggregate3.Inputs, data[4:], &input))
+
+		output := []*result3{}
+		for _, call := range input.Calls {
+			success, res := executeToken(t, call.Target, call.CallData)
+			if !success && !call.AllowFailure {
+				// the whole aggregate3 call reverts
+				w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted: Multicall3: call failed"}}`))
+				return
+			}
+			output = append(output, &result3{Success: success, ReturnData: res})
+		}
+		res, err := abi.Encode([]interface{}{output}, aggregate3.Outputs)
+		assert.NoError(t, err)
+
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x` + hex.EncodeToString(res) + `"}`))
+	}))
+}
+
+func TestMulticall(t *testing.T) {
+	var requests []int
+	srv := newMulticallServer(t, &requests)
+	defer srv.Close()
+
+	provider, err := jsonrpc.NewClient(srv.URL)
+	assert.NoError(t, err)
+
+	token := contract.NewContract(tokenAddr, tokenAbi, provider)
+	calls := []*Call{
+		NewCall(token, "balanceOf", web3.Address{0x1}),
+		NewCall(token, "balanceOf", blockedAddr),
+		NewCall(token, "balanceOf", web3.Address{0x2}),
+		NewCall(token, "transfer", web3.Address{0x2}),
+		NewCall(token, "balanceOf", "not an address"),
+		NewCall(contract.NewContract(emptyAddr, tokenAbi, provider), "balanceOf", web3.Address{0x3}),
+	}
+
+	results, err := NewMulticall(provider).Call(web3.Latest, calls...)
+	assert.NoError(t, err)
+	assert.Len(t, results, len(calls))
+	assert.Len(t, requests, 1)
+
+	assert.True(t, results[0].Success)
+	assert.Equal(t, results[0].Output["balance"], big.NewInt(1))
+
+	assert.False(t, results[1].Success)
+	revertErr, ok := results[1].Err.(*abi.RevertError)
+	assert.True(t, ok)
+	assert.Equal(t, revertErr.Name, "Blocked")
+	assert.Equal(t, revertErr.Args["owner"], blockedAddr)
+
+	assert.True(t, results[2].Success)
+	assert.Equal(t, results[2].Output["balance"], big.NewInt(2))
+
+	// the calls that cannot be encoded are not sent
+	assert.False(t, results[3].Success)
+	assert.Error(t, results[3].Err)
+	assert.False(t, results[4].Success)
+	assert.Error(t, results[4].Err)
+
+	// the target is not a contract
+	assert.False(t, results[5].Success)
+	assert.Error(t, results[5].Err)
+}
+
+func TestMulticall_Split(t *testing.T) {
+	var requests []int
+	srv := newMulticallServer(t, &requests)
+	defer srv.Close()
+
+	provider, err := jsonrpc.NewClient(srv.URL)
+	assert.NoError(t, err)
+
+	token := contract.NewContract(tokenAddr, tokenAbi, provider)
+
+	num := 100
+	calls := []*Call{}
+	for i := 0; i < num; i++ {
+		calls = append(calls, NewCall(token, "balanceOf", web3.Address{byte(i + 1)}))
+	}
+
+	// the encoded input has 68 bytes of selector, offset and length
+	// and balanceOf is 160 bytes of overhead and 64 bytes of calldata,
+	// so 10 calls fit in each request
+	m := NewMulticall(provider, WithMaxCalldataSize(68+10*224))
+
+	results, err := m.Call(web3.Latest, calls...)
+	assert.NoError(t, err)
+	assert.Len(t, requests, 10)
+	for _, size := range requests {
+		assert.Equal(t, size, 68+10*224)
+	}
+
+	for i, res := range results {
+		assert.True(t, res.Success)
+		assert.Equal(t, res.Output["balance"], big.NewInt(int64(i+1)))
+	}
+
+	// a call bigger than the limit is sent alone
+	requests = nil
+	m = NewMulticall(provider, WithMaxCalldataSize(1))
+
+	results, err = m.Call(web3.Latest, calls[:3]...)
+	assert.NoError(t, err)
+	assert.Len(t, requests, 3)
+	assert.Len(t, results, 3)
+	assert.Equal(t, results[2].Output["balance"], big.NewInt(3))
+
+	// the requests do not exceed the limit by one byte
+	requests = nil
+	m = NewMulticall(provider, WithMaxCalldataSize(68+10*224-1))
+
+	_, err = m.Call(web3.Latest, calls[:10]...)
+	assert.NoError(t, err)
+	assert.Len(t, requests, 2)
+	for _, size := range requests {
+		assert.LessOrEqual(t, size, 68+10*224-1)
+	}
+}
+
+func TestMulticall_RequireSuccess(t *testing.T) {
+	var requests []int
+	srv := newMulticallServer(t, &requests)
+	defer srv.Close()
+
+	provider, err := jsonrpc.NewClient(srv.URL)
+	assert.NoError(t, err)
+
+	token := contract.NewContract(tokenAddr, tokenAbi, provider)
+	calls := []*Call{
+		NewCall(token, "balanceOf", web3.Address{0x1}),
+		NewCall(token, "balanceOf", blockedAddr).RequireSuccess(),
+		NewCall(token, "balanceOf", web3.Address{0x2}),
+	}
+
+	// all the calls of the request fail
+	results, err := NewMulticall(provider).Call(web3.Latest, calls...)
+	assert.NoError(t, err)
+	for _, res := range results {
+		assert.False(t, res.Success)
+		assert.Error(t, res.Err)
+	}
+
+	// the calls sent in other requests are not affected
+	results, err = NewMulticall(provider, WithMaxCalldataSize(1)).Call(web3.Latest, calls...)
+	assert.NoError(t, err)
+
+	assert.True(t, results[0].Success)
+	assert.Equal(t, results[0].Output["balance"], big.NewInt(1))
+	assert.False(t, results[1].Success)
+	assert.Contains(t, results[1].Err.Error(), "Multicall3: call failed")
+	assert.True(t, results[2].Success)
+	assert.Equal(t, results[2].Output["balance"], big.NewInt(2))
+}
+
+func TestMulticall_InvalidResponse(t *testing.T) {
+	cases := []string{
+		`""`,
+		`"0"`,
+		`"abcd"`,
+	}
+	for _, c := range cases {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":` + c + `}`))
+		}))
+
+		provider, err := jsonrpc.NewClient(srv.URL)
+		assert.NoError(t, err)
+
+		token := contract.NewContract(tokenAddr, tokenAbi, provider)
+		_, err = NewMulticall(provider).Call(web3.Latest, NewCall(token, "balanceOf", web3.Address{0x1}))
+		assert.Error(t, err)
+
+		srv.Close()
+	}
+}
@@ -0,0 +1,228 @@
+package nonce
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	web3 "github.com/mover-code/golang-web3"
+	"github.com/mover-code/golang-web3/jsonrpc/codec"
+)
+
+// Provider are the eth1x methods required by the nonce manager
+type Provider interface {
+	GetNonce(addr web3.Address, blockNumber web3.BlockNumberOrHash) (uint64, error)
+}
+
+// Manager hands out sequential nonces for the accounts that send
+// transactions. It is safe to use from multiple goroutines and it is meant
+// to be shared by all the senders of the same account.
+type Manager struct {
+	provider Provider
+
+	lock     sync.Mutex
+	accounts map[web3.Address]*account
+}
+
+type account struct {
+	lock   sync.Mutex
+	seeded bool
+	next   uint64
+
+	// reclaimed are the nonces below next that were
+	// not used and are handed out first
+	reclaimed []uint64
+}
+
+// NewManager creates a new nonce manager
+func NewManager(provider Provider) *Manager {
+	return &Manager{
+		provider: provider,
+		accounts: map[web3.Address]*account{},
+	}
+}
+
+func (m *Manager) account(addr web3.Address) *account {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	acct, ok := m.accounts[addr]
+	if !ok {
+		acct = &account{}
+		m.accounts[addr] = acct
+	}
+	return acct
+}
+
+// Next returns the next nonce of the account. The first call for an
+// account seeds the nonce from the pending nonce in the node.
+func (m *Manager) Next(addr web3.Address) (uint64, error) {
+	acct := m.account(addr)
+
+	acct.lock.Lock()
+	defer acct.lock.Unlock()
+
+	if !acct.seeded {
+		if err := m.seed(addr, acct); err != nil {
+			return 0, err
+		}
+	}
+	if len(acct.reclaimed) != 0 {
+		nonce := acct.reclaimed[0]
+		acct.reclaimed = acct.reclaimed[1:]
+		return nonce, nil
+	}
+	nonce := acct.next
+	acct.next++
+	return nonce, nil
+}
+
+func (m *Manager) seed(addr web3.Address, acct *account) error {
+	nonce, err := m.provider.GetNonce(addr, web3.Pending)
+	if err != nil {
+		return err
+	}
+	acct.seeded = true
+	acct.next = nonce
+	acct.reclaimed = nil
+	return nil
+}
+
+// Reclaim returns a nonce that was not used (i.e. the transaction was
+// not accepted by the node) so that it is handed out again
+func (m *Manager) Reclaim(addr web3.Address, nonce uint64) {
+	acct := m.account(addr)
+
+	acct.lock.Lock()
+	defer acct.lock.Unlock()
+
+	if !acct.seeded || nonce >= acct.next {
+		return
+	}
+	if nonce == acct.next-1 {
+		acct.next--
+		// reclaimed nonces right below the new next are not gaps anymore
+		for len(acct.reclaimed) != 0 && acct.reclaimed[len(acct.reclaimed)-1] == acct.next-1 {
+			acct.reclaimed = acct.reclaimed[:len(acct.reclaimed)-1]
+			acct.next--
+		}
+		return
+	}
+
+	indx := sort.Search(len(acct.reclaimed), func(i int) bool {
+		return acct.reclaimed[i] >= nonce
+	})
+	if indx < len(acct.reclaimed) && acct.reclaimed[indx] == nonce {
+		return
+	}
+	acct.reclaimed = append(acct.reclaimed, 0)
+	copy(acct.reclaimed[indx+1:], acct.reclaimed[indx:])
+	acct.reclaimed[indx] = nonce
+}
+
+// Resync seeds again the nonce of the account from the pending nonce in
+// the node. Nonces handed out before and not yet submitted may be repeated.
+func (m *Manager) Resync(addr web3.Address) error {
+	acct := m.account(addr)
+
+	acct.lock.Lock()
+	defer acct.lock.Unlock()
+
+	return m.seed(addr, acct)
+}
+
+// Reset removes the state of the account, which is seeded
+// again with the next nonce
+func (m *Manager) Reset(addr web3.Address) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.accounts, addr)
+}
+
+// Failed updates the account after the submission of a transaction with
+// the nonce fails with err. If the node rejected the nonce, the account is
+// resynced. If the transaction or a replacement is already known to the
+// node, the nonce is used. If the node rejected the transaction for another
+// reason, the nonce is reclaimed. Otherwise, the error happened in the
+// transport and the transaction may have been accepted, so the account is
+// resynced too.
+func (m *Manager) Failed(addr web3.Address, nonce uint64, err error) error {
+	switch {
+	case IsNonceTooLow(err), IsNonceTooHigh(err):
+		return m.Resync(addr)
+	case IsAlreadyKnown(err), IsUnderpriced(err):
+		return nil
+	}
+	var obj *codec.ErrorObject
+	if !errors.As(err, &obj) {
+		return m.Resync(addr)
+	}
+	m.Reclaim(addr, nonce)
+	return nil
+}
+
+var (
+	nonceTooLowMessages = []string{
+		"nonce too low",
+		"nonce is too low",
+		"oldnonce",
+	}
+	nonceTooHighMessages = []string{
+		"nonce too high",
+		"nonce is too high",
+	}
+	alreadyKnownMessages = []string{
+		"already known",
+		"known transaction",
+		"already imported",
+	}
+	underpricedMessages = []string{
+		"replacement transaction underpriced",
+	}
+)
+
+// IsNonceTooLow returns whether the error is the rejection of
+// a transaction because its nonce was already used
+func IsNonceTooLow(err error) bool {
+	return matchError(err, nonceTooLowMessages)
+}
+
+// IsNonceTooHigh returns whether the error is the rejection of
+// a transaction because its nonce leaves a gap
+func IsNonceTooHigh(err error) bool {
+	return matchError(err, nonceTooHighMessages)
+}
+
+// IsAlreadyKnown returns whether the error is the rejection of
+// a transaction because it is already in the pool of the node
+func IsAlreadyKnown(err error) bool {
+	return matchError(err, alreadyKnownMessages)
+}
+
+// IsUnderpriced returns whether the error is the rejection of a
+// transaction because another one with the same nonce and similar
+// fees is already in the pool of the node
+func IsUnderpriced(err error) bool {
+	return matchError(err, underpricedMessages)
+}
+
+func matchError(err error, messages []string) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	var obj *codec.ErrorObject
+	if errors.As(err, &obj) {
+		msg = obj.Message
+	}
+	// some clients use constants like NONCE_TOO_LOW
+	msg = strings.ReplaceAll(strings.ToLower(msg), "_", " ")
+	for _, m := range messages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
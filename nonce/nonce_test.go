@@ -0,0 +1,176 @@
+package nonce
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	web3 "github.com/mover-code/golang-web3"
+	"github.com/mover-code/golang-web3/jsonrpc/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockProvider struct {
+	lock   sync.Mutex
+	nonces map[web3.Address]uint64
+	calls  int
+	err    error
+}
+
+func (m *mockProvider) GetNonce(addr web3.Address, blockNumber web3.BlockNumberOrHash) (uint64, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if blockNumber.Location() != "pending" {
+		return 0, fmt.Errorf("expected pending block but found %s", blockNumber.Location())
+	}
+	m.calls++
+	if m.err != nil {
+		return 0, m.err
+	}
+	return m.nonces[addr], nil
+}
+
+func TestManager_Concurrent(t *testing.T) {
+	addr := web3.Address{0x1}
+	provider := &mockProvider{nonces: map[web3.Address]uint64{addr: 10}}
+	m := NewManager(provider)
+
+	num := 100
+	nonces := make(chan uint64, num)
+
+	var wg sync.WaitGroup
+	for i := 0; i < num; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce, err := m.Next(addr)
+			assert.NoError(t, err)
+			nonces <- nonce
+		}()
+	}
+	wg.Wait()
+	close(nonces)
+
+	found := map[uint64]bool{}
+	for nonce := range nonces {
+		assert.False(t, found[nonce])
+		found[nonce] = true
+	}
+	for i := 10; i < 10+num; i++ {
+		assert.True(t, found[uint64(i)])
+	}
+
+	// the account is only seeded once
+	assert.Equal(t, provider.calls, 1)
+
+	// other accounts have their own nonces
+	nonce, err := m.Next(web3.Address{0x2})
+	assert.NoError(t, err)
+	assert.Equal(t, nonce, uint64(0))
+}
+
+func TestManager_Reclaim(t *testing.T) {
+	addr := web3.Address{0x1}
+	m := NewManager(&mockProvider{nonces: map[web3.Address]uint64{addr: 5}})
+
+	next := func() uint64 {
+		nonce, err := m.Next(addr)
+		assert.NoError(t, err)
+		return nonce
+	}
+
+	// 5, 6, 7, 8
+	for i := 0; i < 4; i++ {
+		next()
+	}
+
+	// the gaps are handed out first in order
+	m.Reclaim(addr, 6)
+	m.Reclaim(addr, 5)
+	m.Reclaim(addr, 5)
+	assert.Equal(t, next(), uint64(5))
+	assert.Equal(t, next(), uint64(6))
+	assert.Equal(t, next(), uint64(9))
+
+	// reclaiming the last nonces moves back the next nonce
+	m.Reclaim(addr, 7)
+	m.Reclaim(addr, 8)
+	m.Reclaim(addr, 9)
+	assert.Equal(t, next(), uint64(7))
+
+	// unknown nonces are ignored
+	m.Reclaim(addr, 100)
+	assert.Equal(t, next(), uint64(8))
+}
+
+func TestManager_Failed(t *testing.T) {
+	addr := web3.Address{0x1}
+	provider := &mockProvider{nonces: map[web3.Address]uint64{addr: 0}}
+	m := NewManager(provider)
+
+	nonce, err := m.Next(addr)
+	assert.NoError(t, err)
+	assert.Equal(t, nonce, uint64(0))
+
+	// a transaction that is not accepted gives back the nonce
+	assert.NoError(t, m.Failed(addr, nonce, &codec.ErrorObject{Code: -32000, Message: "insufficient funds for gas * price + value"}))
+
+	nonce, err = m.Next(addr)
+	assert.NoError(t, err)
+	assert.Equal(t, nonce, uint64(0))
+
+	// the nonce was used by another sender, resync with the node
+	provider.nonces[addr] = 20
+	assert.NoError(t, m.Failed(addr, nonce, &codec.ErrorObject{Code: -32000, Message: "nonce too low"}))
+
+	nonce, err = m.Next(addr)
+	assert.NoError(t, err)
+	assert.Equal(t, nonce, uint64(20))
+
+	// the transaction is already in the pool
+	assert.NoError(t, m.Failed(addr, nonce, fmt.Errorf("already known")))
+
+	nonce, err = m.Next(addr)
+	assert.NoError(t, err)
+	assert.Equal(t, nonce, uint64(21))
+
+	// another transaction with the nonce is already in the pool
+	assert.NoError(t, m.Failed(addr, nonce, &codec.ErrorObject{Code: -32000, Message: "replacement transaction underpriced"}))
+
+	nonce, err = m.Next(addr)
+	assert.NoError(t, err)
+	assert.Equal(t, nonce, uint64(22))
+
+	// the transaction may have reached the node, resync with the node
+	provider.nonces[addr] = 23
+	assert.NoError(t, m.Failed(addr, nonce, fmt.Errorf("context deadline exceeded")))
+
+	nonce, err = m.Next(addr)
+	assert.NoError(t, err)
+	assert.Equal(t, nonce, uint64(23))
+
+	// the errors of the resync are returned
+	provider.err = fmt.Errorf("connection refused")
+	assert.Error(t, m.Failed(addr, nonce, fmt.Errorf("connection reset by peer")))
+}
+
+func TestNonceErrors(t *testing.T) {
+	cases := []struct {
+		err  error
+		low  bool
+		high bool
+	}{
+		{&codec.ErrorObject{Message: "nonce too low"}, true, false},
+		{&codec.ErrorObject{Message: "Transaction nonce is too low. Try incrementing the nonce."}, true, false},
+		{&codec.ErrorObject{Message: "NONCE_TOO_LOW"}, true, false},
+		{fmt.Errorf("OldNonce, Current nonce: 5, nonce of rejected tx: 4"), true, false},
+		{&codec.ErrorObject{Message: "nonce too high"}, false, true},
+		{fmt.Errorf("execution reverted"), false, false},
+		{nil, false, false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, IsNonceTooLow(c.err), c.low)
+		assert.Equal(t, IsNonceTooHigh(c.err), c.high)
+	}
+}
@@ -108,34 +108,102 @@ type Block struct {
 	GasLimit           uint64
 	GasUsed            uint64
 	Timestamp          uint64
+	LogsBloom          []byte
+	MixHash            Hash
+	Nonce              [8]byte
 	Transactions       []*Transaction
 	TransactionsHashes []Hash
 	Uncles             []Hash
+
+	// london values
+	BaseFeePerGas *big.Int
+
+	// shanghai values
+	WithdrawalsRoot *Hash
+
+	// cancun values
+	BlobGasUsed           *uint64
+	ExcessBlobGas         *uint64
+	ParentBeaconBlockRoot *Hash
+
+	// prague values
+	RequestsHash *Hash
+}
+
+// TransactionType is the EIP-2718 type of a transaction
+type TransactionType int
+
+const (
+	// TransactionLegacy is a pre EIP-2718 transaction
+	TransactionLegacy TransactionType = 0
+
+	// TransactionAccessList is an EIP-2930 transaction
+	TransactionAccessList TransactionType = 1
+
+	// TransactionDynamicFee is an EIP-1559 transaction
+	TransactionDynamicFee TransactionType = 2
+
+	// TransactionBlob is an EIP-4844 transaction
+	TransactionBlob TransactionType = 3
+)
+
+// hasDynamicFee returns whether the transactions of
+// the type have EIP-1559 fees instead of a gas price
+func (t TransactionType) hasDynamicFee() bool {
+	return t == TransactionDynamicFee || t == TransactionBlob
 }
 
 type Transaction struct {
-	Hash        Hash
-	From        Address
-	To          *Address
-	Input       []byte
-	GasPrice    uint64
-	Gas         uint64
-	Value       *big.Int
-	Nonce       uint64
-	V           []byte
-	R           []byte
-	S           []byte
+	Type TransactionType
+
+	// legacy values
+	Hash     Hash
+	From     Address
+	To       *Address
+	Input    []byte
+	GasPrice uint64
+	Gas      uint64
+	Value    *big.Int
+	Nonce    uint64
+	V        []byte
+	R        []byte
+	S        []byte
+
+	// jsonrpc values
 	BlockHash   Hash
 	BlockNumber uint64
 	TxnIndex    uint64
+
+	// eip-2930 values
+	ChainID    *big.Int
+	AccessList AccessList
+
+	// eip-1559 values
+	MaxPriorityFeePerGas *big.Int
+	MaxFeePerGas         *big.Int
+
+	// eip-4844 values
+	MaxFeePerBlobGas    *big.Int
+	BlobVersionedHashes []Hash
 }
 
+// AccessEntry is an address and the storage keys it touches (EIP-2930)
+type AccessEntry struct {
+	Address Address
+	Storage []Hash
+}
+
+// AccessList is the list of addresses and storage keys
+// a transaction plans to access (EIP-2930)
+type AccessList []AccessEntry
+
 type CallMsg struct {
-	From     Address
-	To       *Address
-	Data     []byte
-	GasPrice uint64
-	Value    *big.Int
+	From       Address
+	To         *Address
+	Data       []byte
+	GasPrice   uint64
+	Value      *big.Int
+	AccessList AccessList
 }
 
 type LogFilter struct {
@@ -161,6 +229,9 @@ func (l *LogFilter) SetTo(b BlockNumber) {
 }
 
 type Receipt struct {
+	Type              TransactionType
+	Status            uint64
+	Root              *Hash
 	TransactionHash   Hash
 	TransactionIndex  uint64
 	ContractAddress   Address
@@ -173,6 +244,40 @@ type Receipt struct {
 	Logs              []*Log
 }
 
+// AccountProof is the merkle proof of an account and
+// some of its storage slots (eth_getProof)
+type AccountProof struct {
+	Address      Address
+	AccountProof [][]byte
+	Balance      *big.Int
+	CodeHash     Hash
+	Nonce        uint64
+	StorageHash  Hash
+	StorageProof []*StorageProof
+}
+
+// StorageProof is the merkle proof of a storage slot
+type StorageProof struct {
+	Key   Hash
+	Value *big.Int
+	Proof [][]byte
+}
+
+// FeeHistory is the base fee and the priority fee rewards
+// of a range of blocks (eth_feeHistory)
+type FeeHistory struct {
+	OldestBlock uint64
+
+	// BaseFeePerGas includes the base fee of the block
+	// after the newest block of the range
+	BaseFeePerGas []*big.Int
+	GasUsedRatio  []float64
+
+	// Reward are the priority fees of the requested
+	// percentiles for each block
+	Reward [][]*big.Int
+}
+
 type Log struct {
 	Removed          bool
 	LogIndex         uint64
@@ -189,8 +294,8 @@ type BlockNumber int
 
 const (
 	Latest   BlockNumber = -1
-	Earliest             = -2
-	Pending              = -3
+	Earliest BlockNumber = -2
+	Pending  BlockNumber = -3
 )
 
 func (b BlockNumber) Location() string {
@@ -223,6 +328,16 @@ type BlockNumberOrHash interface {
 	Location() string
 }
 
+// Key is an account that signs hashes, either a local
+// private key or a remote signer
+type Key interface {
+	// Address returns the address of the account
+	Address() Address
+
+	// Sign signs the hash and returns the 65 bytes [R || S || V] signature
+	Sign(hash []byte) ([]byte, error)
+}
+
 func (b *Block) Copy() *Block {
 	bb := new(Block)
 	*bb = *b
@@ -4,9 +4,14 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"testing"
 	"text/template"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/mover-code/golang-web3/fastrlp"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -95,6 +100,91 @@ func TestJSONEncoding(t *testing.T) {
 			}`,
 			build: txn,
 		},
+		{
+			Input: `{
+				"hash": "{{.Hash1}}",
+				"from": "{{.Addr1}}",
+				"input": "0x00",
+				"value": "0x0",
+				"gas": "0x0",
+				"nonce": "0x10",
+				"to": "{{.Addr1}}",
+				"v":"0x01",
+				"r":"{{.Hash1}}",
+				"s":"{{.Hash1}}",
+				"blockHash": "{{.Hash0}}",
+				"blockNumber": "0x0",
+				"transactionIndex": "0x0",
+				"type": "0x2",
+				"chainId": "0x1",
+				"accessList": [],
+				"maxPriorityFeePerGas": "0x10",
+				"maxFeePerGas": "0x20"
+			}`,
+			build: txn,
+		},
+		{
+			Input: `{
+				"hash": "{{.Hash1}}",
+				"from": "{{.Addr1}}",
+				"input": "0x00",
+				"value": "0x0",
+				"gas": "0x0",
+				"nonce": "0x10",
+				"to": "{{.Addr1}}",
+				"v":"0x01",
+				"r":"{{.Hash1}}",
+				"s":"{{.Hash1}}",
+				"blockHash": "{{.Hash0}}",
+				"blockNumber": "0x0",
+				"transactionIndex": "0x0",
+				"type": "0x3",
+				"chainId": "0x1",
+				"accessList": [],
+				"maxPriorityFeePerGas": "0x10",
+				"maxFeePerGas": "0x20",
+				"maxFeePerBlobGas": "0x30",
+				"blobVersionedHashes": [
+					"{{.Hash1}}",
+					"{{.Hash2}}"
+				]
+			}`,
+			build: txn,
+		},
+		{
+			Input: `{
+				"hash": "{{.Hash1}}",
+				"from": "{{.Addr1}}",
+				"input": "0x00",
+				"value": "0x0",
+				"gasPrice": "0x10",
+				"gas": "0x0",
+				"nonce": "0x10",
+				"to": "{{.Addr1}}",
+				"v":"0x01",
+				"r":"{{.Hash1}}",
+				"s":"{{.Hash1}}",
+				"blockHash": "{{.Hash0}}",
+				"blockNumber": "0x0",
+				"transactionIndex": "0x0",
+				"type": "0x1",
+				"chainId": "0x1",
+				"accessList": [
+					{
+						"address": "{{.Addr2}}",
+						"storageKeys": [
+							"{{.Hash1}}",
+							"{{.Hash2}}"
+						]
+					},
+					{
+						"address": "{{.Addr3}}",
+						"storageKeys": []
+					}
+				]
+			}`,
+			build: txn,
+		},
 	}
 
 	for _, c := range cases {
@@ -124,6 +214,285 @@ func TestJSONEncoding(t *testing.T) {
 	}
 }
 
+func TestTransactionRLPEncoding(t *testing.T) {
+	to := Address{0x1}
+
+	cases := []*Transaction{
+		{
+			Nonce:    1,
+			GasPrice: 10,
+			Gas:      21000,
+			To:       &to,
+			Value:    big.NewInt(1),
+			V:        []byte{0x25},
+			R:        []byte{0x1},
+			S:        []byte{0x2},
+		},
+		{
+			// contract creation
+			Nonce: 2,
+			Gas:   100000,
+			Value: big.NewInt(0),
+			Input: []byte{0x60, 0x80},
+			V:     []byte{0x1b},
+			R:     []byte{0x1},
+			S:     []byte{0x2},
+		},
+		{
+			Type:     TransactionAccessList,
+			ChainID:  big.NewInt(1),
+			Nonce:    3,
+			GasPrice: 10,
+			Gas:      21000,
+			To:       &to,
+			Value:    big.NewInt(0),
+			AccessList: AccessList{
+				{Address: Address{0x2}, Storage: []Hash{{0x1}}},
+			},
+			V: []byte{0x1},
+			R: []byte{0x1},
+			S: []byte{0x2},
+		},
+		{
+			Type:                 TransactionDynamicFee,
+			ChainID:              big.NewInt(1),
+			Nonce:                4,
+			MaxPriorityFeePerGas: big.NewInt(1),
+			MaxFeePerGas:         big.NewInt(100),
+			Gas:                  21000,
+			To:                   &to,
+			Value:                big.NewInt(0),
+			R:                    []byte{0x1},
+			S:                    []byte{0x2},
+		},
+		{
+			Type:                 TransactionBlob,
+			ChainID:              big.NewInt(1),
+			Nonce:                5,
+			MaxPriorityFeePerGas: big.NewInt(1),
+			MaxFeePerGas:         big.NewInt(100),
+			Gas:                  21000,
+			To:                   &to,
+			Value:                big.NewInt(0),
+			MaxFeePerBlobGas:     big.NewInt(10),
+			BlobVersionedHashes:  []Hash{{0x1, 0x1}, {0x1, 0x2}},
+			V:                    []byte{0x1},
+			R:                    []byte{0x1},
+			S:                    []byte{0x2},
+		},
+	}
+
+	for _, c := range cases {
+		raw := c.MarshalRLP()
+
+		txn := new(Transaction)
+		assert.NoError(t, txn.UnmarshalRLP(raw))
+		assert.Equal(t, txn.Hash, BytesToHash(Keccak256(raw)))
+		assert.Equal(t, txn.MarshalRLP(), raw)
+
+		assert.Equal(t, txn.Type, c.Type)
+		assert.Equal(t, txn.Nonce, c.Nonce)
+		assert.Equal(t, txn.To, c.To)
+		assert.Equal(t, txn.Input, c.Input)
+		assert.Equal(t, txn.BlobVersionedHashes, c.BlobVersionedHashes)
+	}
+
+	// unknown transaction type
+	assert.Error(t, new(Transaction).UnmarshalRLP([]byte{0x5, 0xc0}))
+}
+
+func TestBlockHeaderHash(t *testing.T) {
+	header := &types.Header{
+		ParentHash:  common.Hash{0x1},
+		UncleHash:   types.EmptyUncleHash,
+		Coinbase:    common.Address{0x2},
+		Root:        common.Hash{0x3},
+		TxHash:      types.EmptyRootHash,
+		ReceiptHash: types.EmptyRootHash,
+		Bloom:       types.Bloom{0x4},
+		Difficulty:  big.NewInt(0),
+		Number:      big.NewInt(15537394),
+		GasLimit:    30000000,
+		GasUsed:     21000,
+		Time:        1663224179,
+		Extra:       []byte{0x1, 0x2},
+		MixDigest:   common.Hash{0x5},
+		Nonce:       types.EncodeNonce(7),
+		BaseFee:     big.NewInt(1000000000),
+	}
+
+	b := &Block{
+		Number:           header.Number.Uint64(),
+		ParentHash:       Hash(header.ParentHash),
+		Sha3Uncles:       Hash(header.UncleHash),
+		TransactionsRoot: Hash(header.TxHash),
+		StateRoot:        Hash(header.Root),
+		ReceiptsRoot:     Hash(header.ReceiptHash),
+		Miner:            Address(header.Coinbase),
+		Difficulty:       header.Difficulty,
+		ExtraData:        header.Extra,
+		GasLimit:         header.GasLimit,
+		GasUsed:          header.GasUsed,
+		Timestamp:        header.Time,
+		LogsBloom:        header.Bloom.Bytes(),
+		MixHash:          Hash(header.MixDigest),
+		Nonce:            header.Nonce,
+		BaseFeePerGas:    header.BaseFee,
+	}
+	assert.Equal(t, b.ComputeHash().String(), header.Hash().String())
+
+	// the header round trips over json
+	data, err := b.MarshalJSON()
+	assert.NoError(t, err)
+
+	b1 := new(Block)
+	assert.NoError(t, b1.UnmarshalJSON(data))
+	assert.Equal(t, b1.ComputeHash(), b.ComputeHash())
+
+	// cancun and prague headers append fields at the end
+	blobGasUsed, excessBlobGas := uint64(131072), uint64(0)
+
+	b.WithdrawalsRoot = &Hash{0x6}
+	b.BlobGasUsed = &blobGasUsed
+	b.ExcessBlobGas = &excessBlobGas
+	b.ParentBeaconBlockRoot = &Hash{0x7}
+	b.RequestsHash = &Hash{0x8}
+
+	// extend the london header encoded by go-ethereum with the new fields
+	headerFields, err := rlp.EncodeToBytes(header)
+	assert.NoError(t, err)
+
+	elems, _, err := rlp.SplitList(headerFields)
+	assert.NoError(t, err)
+
+	expected, err := rlp.EncodeToBytes([]interface{}{
+		rlp.RawValue(elems),
+		common.Hash(*b.WithdrawalsRoot),
+		blobGasUsed,
+		excessBlobGas,
+		common.Hash(*b.ParentBeaconBlockRoot),
+		common.Hash(*b.RequestsHash),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, b.MarshalRLPHeader(), expected)
+}
+
+func TestBlockHeaderReusedArena(t *testing.T) {
+	b0 := &Block{
+		ParentHash: Hash{0x1},
+		Miner:      Address{0x2},
+		Difficulty: big.NewInt(1),
+		ExtraData:  []byte{0x3},
+	}
+	b1 := &Block{
+		ParentHash: Hash{0x4},
+		Miner:      Address{0x5},
+		Difficulty: big.NewInt(2),
+		ExtraData:  []byte{0x6},
+		LogsBloom:  bytes.Repeat([]byte{0x7}, 256),
+		MixHash:    Hash{0x8},
+	}
+	expected0, expected1 := b0.MarshalRLPHeader(), b1.MarshalRLPHeader()
+
+	// the arena reuses the values of the first header for the second one
+	ar := &fastrlp.Arena{}
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, b0.MarshalRLPHeaderWith(ar).MarshalTo(nil), expected0)
+		ar.Reset()
+		assert.Equal(t, b1.MarshalRLPHeaderWith(ar).MarshalTo(nil), expected1)
+		ar.Reset()
+	}
+
+	assert.Equal(t, b0.ParentHash, Hash{0x1})
+	assert.Equal(t, b0.Miner, Address{0x2})
+	assert.Equal(t, zeroBloom, make([]byte, 256))
+}
+
+func TestAccountProofJSONDecoding(t *testing.T) {
+	input := `{
+		"address": "0x0100000000000000000000000000000000000000",
+		"accountProof": ["0xf8518080", "0xe2"],
+		"balance": "0x10",
+		"codeHash": "0xc5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470",
+		"nonce": "0x2",
+		"storageHash": "0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421",
+		"storageProof": [
+			{
+				"key": "0x1",
+				"value": "0x0",
+				"proof": []
+			}
+		]
+	}`
+
+	proof := new(AccountProof)
+	assert.NoError(t, json.Unmarshal([]byte(input), proof))
+
+	assert.Equal(t, proof.Address, Address{0x1})
+	assert.Equal(t, proof.AccountProof, [][]byte{{0xf8, 0x51, 0x80, 0x80}, {0xe2}})
+	assert.Equal(t, proof.Balance, big.NewInt(16))
+	assert.Equal(t, proof.Nonce, uint64(2))
+	assert.Len(t, proof.StorageProof, 1)
+	assert.Equal(t, proof.StorageProof[0].Key, BytesToHash([]byte{0x1}))
+	assert.Equal(t, proof.StorageProof[0].Value.Uint64(), uint64(0))
+	assert.Empty(t, proof.StorageProof[0].Proof)
+}
+
+func TestLogFilterJSONEncoding(t *testing.T) {
+	from := BlockNumber(1)
+
+	cases := []struct {
+		filter   *LogFilter
+		expected string
+	}{
+		{
+			&LogFilter{},
+			`{"topics":[]}`,
+		},
+		{
+			&LogFilter{Address: []Address{{0x1}}, From: &from},
+			`{"address":"0x0100000000000000000000000000000000000000","topics":[],"fromBlock":"0x1"}`,
+		},
+		{
+			&LogFilter{Address: []Address{{0x1}, {0x2}}},
+			`{"address":["0x0100000000000000000000000000000000000000","0x0200000000000000000000000000000000000000"],"topics":[]}`,
+		},
+	}
+	for _, c := range cases {
+		data, err := c.filter.MarshalJSON()
+		assert.NoError(t, err)
+		assert.Equal(t, string(data), c.expected)
+	}
+}
+
+func TestFeeHistoryJSONDecoding(t *testing.T) {
+	input := `{
+		"oldestBlock": "0x10",
+		"baseFeePerGas": ["0x3b9aca00", "0x3b9aca01", "0x0"],
+		"gasUsedRatio": [0.5, 1],
+		"reward": [["0x1", "0x2"], ["0x3", "0x4"]]
+	}`
+
+	history := new(FeeHistory)
+	assert.NoError(t, json.Unmarshal([]byte(input), history))
+
+	assert.Equal(t, history.OldestBlock, uint64(16))
+	assert.Len(t, history.BaseFeePerGas, 3)
+	assert.Equal(t, history.BaseFeePerGas[0], big.NewInt(1000000000))
+	assert.Equal(t, history.BaseFeePerGas[1], big.NewInt(1000000001))
+	assert.Equal(t, history.BaseFeePerGas[2].Sign(), 0)
+	assert.Equal(t, history.GasUsedRatio, []float64{0.5, 1})
+	assert.Equal(t, history.Reward, [][]*big.Int{
+		{big.NewInt(1), big.NewInt(2)},
+		{big.NewInt(3), big.NewInt(4)},
+	})
+
+	// the reward is optional
+	history = new(FeeHistory)
+	assert.NoError(t, json.Unmarshal([]byte(`{"oldestBlock": "0x1", "baseFeePerGas": ["0x1"], "gasUsedRatio": []}`), history))
+	assert.Empty(t, history.Reward)
+}
+
 func compactJSON(s string) string {
 	buffer := new(bytes.Buffer)
 	if err := json.Compact(buffer, []byte(s)); err != nil {
@@ -61,6 +61,33 @@ func (t *Block) MarshalJSON() ([]byte, error) {
 	o.Set("timestamp", a.NewString(fmt.Sprintf("0x%x", t.Timestamp)))
 	o.Set("difficulty", a.NewString(fmt.Sprintf("0x%x", t.Difficulty)))
 	o.Set("extraData", a.NewString("0x"+hex.EncodeToString(t.ExtraData)))
+	if len(t.LogsBloom) != 0 {
+		o.Set("logsBloom", a.NewString("0x"+hex.EncodeToString(t.LogsBloom)))
+	}
+	if t.MixHash != ZeroHash {
+		o.Set("mixHash", a.NewString(t.MixHash.String()))
+	}
+	if t.Nonce != [8]byte{} {
+		o.Set("nonce", a.NewString("0x"+hex.EncodeToString(t.Nonce[:])))
+	}
+	if t.BaseFeePerGas != nil {
+		o.Set("baseFeePerGas", a.NewString(fmt.Sprintf("0x%x", t.BaseFeePerGas)))
+	}
+	if t.WithdrawalsRoot != nil {
+		o.Set("withdrawalsRoot", a.NewString(t.WithdrawalsRoot.String()))
+	}
+	if t.BlobGasUsed != nil {
+		o.Set("blobGasUsed", a.NewString(fmt.Sprintf("0x%x", *t.BlobGasUsed)))
+	}
+	if t.ExcessBlobGas != nil {
+		o.Set("excessBlobGas", a.NewString(fmt.Sprintf("0x%x", *t.ExcessBlobGas)))
+	}
+	if t.ParentBeaconBlockRoot != nil {
+		o.Set("parentBeaconBlockRoot", a.NewString(t.ParentBeaconBlockRoot.String()))
+	}
+	if t.RequestsHash != nil {
+		o.Set("requestsHash", a.NewString(t.RequestsHash.String()))
+	}
 
 	// uncles
 	if len(t.Uncles) != 0 {
@@ -89,7 +116,9 @@ func (t *Transaction) MarshalJSON() ([]byte, error) {
 	if t.Value != nil {
 		o.Set("value", a.NewString(fmt.Sprintf("0x%x", t.Value)))
 	}
-	o.Set("gasPrice", a.NewString(fmt.Sprintf("0x%x", t.GasPrice)))
+	if !t.Type.hasDynamicFee() || t.GasPrice != 0 {
+		o.Set("gasPrice", a.NewString(fmt.Sprintf("0x%x", t.GasPrice)))
+	}
 	o.Set("gas", a.NewString(fmt.Sprintf("0x%x", t.Gas)))
 	if t.Nonce != 0 {
 		// we can remove this once we include support for custom nonces
@@ -108,6 +137,32 @@ func (t *Transaction) MarshalJSON() ([]byte, error) {
 	o.Set("blockNumber", a.NewString(fmt.Sprintf("0x%x", t.BlockNumber)))
 	o.Set("transactionIndex", a.NewString(fmt.Sprintf("0x%x", t.TxnIndex)))
 
+	if t.Type != TransactionLegacy {
+		o.Set("type", a.NewString(fmt.Sprintf("0x%x", int(t.Type))))
+		if t.ChainID != nil {
+			o.Set("chainId", a.NewString(fmt.Sprintf("0x%x", t.ChainID)))
+		}
+		o.Set("accessList", t.AccessList.marshalJSON(a))
+	}
+	if t.Type.hasDynamicFee() {
+		if t.MaxPriorityFeePerGas != nil {
+			o.Set("maxPriorityFeePerGas", a.NewString(fmt.Sprintf("0x%x", t.MaxPriorityFeePerGas)))
+		}
+		if t.MaxFeePerGas != nil {
+			o.Set("maxFeePerGas", a.NewString(fmt.Sprintf("0x%x", t.MaxFeePerGas)))
+		}
+	}
+	if t.Type == TransactionBlob {
+		if t.MaxFeePerBlobGas != nil {
+			o.Set("maxFeePerBlobGas", a.NewString(fmt.Sprintf("0x%x", t.MaxFeePerBlobGas)))
+		}
+		hashes := a.NewArray()
+		for i, h := range t.BlobVersionedHashes {
+			hashes.SetArrayItem(i, a.NewString(h.String()))
+		}
+		o.Set("blobVersionedHashes", hashes)
+	}
+
 	res := o.MarshalTo(nil)
 	defaultArena.Put(a)
 	return res, nil
@@ -131,12 +186,41 @@ func (c *CallMsg) MarshalJSON() ([]byte, error) {
 	if c.Value != nil {
 		o.Set("value", a.NewString(fmt.Sprintf("0x%x", c.Value)))
 	}
+	if len(c.AccessList) != 0 {
+		o.Set("accessList", c.AccessList.marshalJSON(a))
+	}
 
 	res := o.MarshalTo(nil)
 	defaultArena.Put(a)
 	return res, nil
 }
 
+// MarshalJSON implements the Marshal interface.
+func (al AccessList) MarshalJSON() ([]byte, error) {
+	a := defaultArena.Get()
+
+	res := al.marshalJSON(a).MarshalTo(nil)
+	defaultArena.Put(a)
+	return res, nil
+}
+
+func (al AccessList) marshalJSON(a *fastjson.Arena) *fastjson.Value {
+	v := a.NewArray()
+	for indx, entry := range al {
+		o := a.NewObject()
+		o.Set("address", a.NewString(entry.Address.String()))
+
+		storage := a.NewArray()
+		for i, key := range entry.Storage {
+			storage.SetArrayItem(i, a.NewString(key.String()))
+		}
+		o.Set("storageKeys", storage)
+
+		v.SetArrayItem(indx, o)
+	}
+	return v
+}
+
 // MarshalJSON implements the Marshal interface.
 func (l *LogFilter) MarshalJSON() ([]byte, error) {
 	a := defaultArena.Get()
@@ -149,6 +233,7 @@ func (l *LogFilter) MarshalJSON() ([]byte, error) {
 		for indx, addr := range l.Address {
 			v.SetArrayItem(indx, a.NewString(addr.String()))
 		}
+		o.Set("address", v)
 	}
 
 	v := a.NewArray()
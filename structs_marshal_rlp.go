@@ -2,25 +2,116 @@ package web3
 
 import "github.com/mover-code/golang-web3/fastrlp"
 
-func (t *Transaction) MarshalRLP() []byte {
+var zeroBloom = make([]byte, 256)
+
+// MarshalRLPHeader marshals the header of the block to RLP
+func (b *Block) MarshalRLPHeader() []byte {
 	ar := fastrlp.DefaultArenaPool.Get()
-	v := t.MarshalRLPWith(ar)
+	v := b.MarshalRLPHeaderWith(ar)
 	data := v.MarshalTo(nil)
 	fastrlp.DefaultArenaPool.Put(ar)
 	return data
 }
 
-// MarshalRLPWith marshals the transaction to RLP with a specific fastrlp.Arena
+// ComputeHash computes the hash of the block from the fields in the header.
+// It can be compared against Hash to check the block returned by a node.
+func (b *Block) ComputeHash() Hash {
+	return BytesToHash(Keccak256(b.MarshalRLPHeader()))
+}
+
+// MarshalRLPHeaderWith marshals the header of the block to RLP with a specific fastrlp.Arena.
+// Optional fields introduced in later forks are only included if they are set.
+func (b *Block) MarshalRLPHeaderWith(arena *fastrlp.Arena) *fastrlp.Value {
+	vv := arena.NewArray()
+
+	// the arena recycles the values and writes on top of their buffers,
+	// always copy the bytes to not modify the block.
+
+	vv.Set(arena.NewCopyBytes(b.ParentHash[:]))
+	vv.Set(arena.NewCopyBytes(b.Sha3Uncles[:]))
+	vv.Set(arena.NewCopyBytes(b.Miner[:]))
+	vv.Set(arena.NewCopyBytes(b.StateRoot[:]))
+	vv.Set(arena.NewCopyBytes(b.TransactionsRoot[:]))
+	vv.Set(arena.NewCopyBytes(b.ReceiptsRoot[:]))
+	if len(b.LogsBloom) == 0 {
+		vv.Set(arena.NewCopyBytes(zeroBloom))
+	} else {
+		vv.Set(arena.NewCopyBytes(b.LogsBloom))
+	}
+	vv.Set(arena.NewBigInt(b.Difficulty))
+	vv.Set(arena.NewUint(b.Number))
+	vv.Set(arena.NewUint(b.GasLimit))
+	vv.Set(arena.NewUint(b.GasUsed))
+	vv.Set(arena.NewUint(b.Timestamp))
+	vv.Set(arena.NewCopyBytes(b.ExtraData))
+	vv.Set(arena.NewCopyBytes(b.MixHash[:]))
+	vv.Set(arena.NewCopyBytes(b.Nonce[:]))
+
+	// each fork appends new fields at the end of the header,
+	// a later field implies that all the previous ones are set.
+	if b.BaseFeePerGas != nil {
+		vv.Set(arena.NewBigInt(b.BaseFeePerGas))
+	}
+	if b.WithdrawalsRoot != nil {
+		vv.Set(arena.NewCopyBytes(b.WithdrawalsRoot[:]))
+	}
+	if b.BlobGasUsed != nil {
+		vv.Set(arena.NewUint(*b.BlobGasUsed))
+	}
+	if b.ExcessBlobGas != nil {
+		vv.Set(arena.NewUint(*b.ExcessBlobGas))
+	}
+	if b.ParentBeaconBlockRoot != nil {
+		vv.Set(arena.NewCopyBytes(b.ParentBeaconBlockRoot[:]))
+	}
+	if b.RequestsHash != nil {
+		vv.Set(arena.NewCopyBytes(b.RequestsHash[:]))
+	}
+	return vv
+}
+
+// MarshalRLP marshals the transaction to RLP. Typed transactions (EIP-2718)
+// are prefixed with the type byte. Only the types up to TransactionBlob are
+// supported, the blob transactions are encoded without the blobs.
+func (t *Transaction) MarshalRLP() []byte {
+	return t.MarshalRLPTo(nil)
+}
+
+// MarshalRLPTo appends the RLP encoding of the transaction to dst
+func (t *Transaction) MarshalRLPTo(dst []byte) []byte {
+	if t.Type != TransactionLegacy {
+		dst = append(dst, byte(t.Type))
+	}
+
+	ar := fastrlp.DefaultArenaPool.Get()
+	v := t.MarshalRLPWith(ar)
+	dst = v.MarshalTo(dst)
+	fastrlp.DefaultArenaPool.Put(ar)
+	return dst
+}
+
+// MarshalRLPWith marshals the transaction to RLP with a specific fastrlp.Arena.
+// For typed transactions it only returns the payload without the type byte.
 func (t *Transaction) MarshalRLPWith(arena *fastrlp.Arena) *fastrlp.Value {
 	vv := arena.NewArray()
 
+	if t.Type != TransactionLegacy {
+		vv.Set(arena.NewBigInt(t.ChainID))
+	}
+
 	vv.Set(arena.NewUint(t.Nonce))
-	vv.Set(arena.NewUint(t.GasPrice))
+
+	if t.Type.hasDynamicFee() {
+		vv.Set(arena.NewBigInt(t.MaxPriorityFeePerGas))
+		vv.Set(arena.NewBigInt(t.MaxFeePerGas))
+	} else {
+		vv.Set(arena.NewUint(t.GasPrice))
+	}
 	vv.Set(arena.NewUint(t.Gas))
 
 	// Address may be empty
 	if t.To != nil {
-		vv.Set(arena.NewBytes((*t.To)[:]))
+		vv.Set(arena.NewCopyBytes((*t.To)[:]))
 	} else {
 		vv.Set(arena.NewNull())
 	}
@@ -28,10 +119,120 @@ func (t *Transaction) MarshalRLPWith(arena *fastrlp.Arena) *fastrlp.Value {
 	vv.Set(arena.NewBigInt(t.Value))
 	vv.Set(arena.NewCopyBytes(t.Input))
 
-	// signature values
-	vv.Set(arena.NewCopyBytes(t.V))
-	vv.Set(arena.NewCopyBytes(t.R))
-	vv.Set(arena.NewCopyBytes(t.S))
+	if t.Type != TransactionLegacy {
+		vv.Set(t.AccessList.MarshalRLPWith(arena))
+	}
+	if t.Type == TransactionBlob {
+		vv.Set(arena.NewBigInt(t.MaxFeePerBlobGas))
+		vv.Set(marshalHashes(arena, t.BlobVersionedHashes))
+	}
+
+	// signature values are integers, they cannot have leading zeros
+	vv.Set(arena.NewCopyBytes(TrimLeftZeros(t.V)))
+	vv.Set(arena.NewCopyBytes(TrimLeftZeros(t.R)))
+	vv.Set(arena.NewCopyBytes(TrimLeftZeros(t.S)))
 
 	return vv
 }
+
+func marshalHashes(arena *fastrlp.Arena, hashes []Hash) *fastrlp.Value {
+	if len(hashes) == 0 {
+		return arena.NewNullArray()
+	}
+	v := arena.NewArray()
+	for _, h := range hashes {
+		v.Set(arena.NewCopyBytes(h[:]))
+	}
+	return v
+}
+
+// MarshalRLPWith marshals the access list to RLP with a specific fastrlp.Arena
+func (a AccessList) MarshalRLPWith(arena *fastrlp.Arena) *fastrlp.Value {
+	if len(a) == 0 {
+		return arena.NewNullArray()
+	}
+
+	v := arena.NewArray()
+	for _, entry := range a {
+		vv := arena.NewArray()
+		vv.Set(arena.NewCopyBytes(entry.Address[:]))
+
+		storage := arena.NewArray()
+		for _, key := range entry.Storage {
+			storage.Set(arena.NewCopyBytes(key[:]))
+		}
+		vv.Set(storage)
+
+		v.Set(vv)
+	}
+	return v
+}
+
+// MarshalRLP marshals the receipt to RLP as it is stored in the receipts trie.
+// Typed receipts are prefixed with the type byte.
+func (r *Receipt) MarshalRLP() []byte {
+	return r.MarshalRLPTo(nil)
+}
+
+// MarshalRLPTo appends the RLP encoding of the receipt to dst
+func (r *Receipt) MarshalRLPTo(dst []byte) []byte {
+	if r.Type != TransactionLegacy {
+		dst = append(dst, byte(r.Type))
+	}
+
+	ar := fastrlp.DefaultArenaPool.Get()
+	v := r.MarshalRLPWith(ar)
+	dst = v.MarshalTo(dst)
+	fastrlp.DefaultArenaPool.Put(ar)
+	return dst
+}
+
+// MarshalRLPWith marshals the receipt to RLP with a specific fastrlp.Arena
+func (r *Receipt) MarshalRLPWith(arena *fastrlp.Arena) *fastrlp.Value {
+	vv := arena.NewArray()
+
+	if r.Root != nil {
+		vv.Set(arena.NewCopyBytes(r.Root[:]))
+	} else {
+		vv.Set(arena.NewUint(r.Status))
+	}
+	vv.Set(arena.NewUint(r.CumulativeGasUsed))
+	if len(r.LogsBloom) == 0 {
+		vv.Set(arena.NewCopyBytes(zeroBloom))
+	} else {
+		vv.Set(arena.NewCopyBytes(r.LogsBloom))
+	}
+
+	logs := arena.NewArray()
+	for _, log := range r.Logs {
+		logs.Set(log.MarshalRLPWith(arena))
+	}
+	vv.Set(logs)
+
+	return vv
+}
+
+// MarshalRLPWith marshals the log to RLP with a specific fastrlp.Arena
+func (l *Log) MarshalRLPWith(arena *fastrlp.Arena) *fastrlp.Value {
+	vv := arena.NewArray()
+	vv.Set(arena.NewCopyBytes(l.Address[:]))
+
+	topics := arena.NewArray()
+	for _, topic := range l.Topics {
+		topics.Set(arena.NewCopyBytes(topic[:]))
+	}
+	vv.Set(topics)
+
+	vv.Set(arena.NewCopyBytes(l.Data))
+	return vv
+}
+
+// TrimLeftZeros returns the bytes without the leading zeros, which is
+// the minimal big endian encoding of an integer
+func TrimLeftZeros(b []byte) []byte {
+	i := 0
+	for i < len(b) && b[i] == 0 {
+		i++
+	}
+	return b[i:]
+}
@@ -62,6 +62,46 @@ func (b *Block) UnmarshalJSON(buf []byte) error {
 		return err
 	}
 
+	// header fields that are not returned by every node
+	if fieldNotFull(v, "logsBloom") {
+		if b.LogsBloom, err = decodeBytes(b.LogsBloom[:0], v, "logsBloom", 256); err != nil {
+			return err
+		}
+	}
+	if fieldNotFull(v, "mixHash") {
+		if err := decodeHash(&b.MixHash, v, "mixHash"); err != nil {
+			return err
+		}
+	}
+	if fieldNotFull(v, "nonce") {
+		if err := unmarshalTextByte(b.Nonce[:], v.GetStringBytes("nonce"), 8); err != nil {
+			return err
+		}
+	}
+
+	// fork specific header fields
+	b.BaseFeePerGas = nil
+	if fieldNotFull(v, "baseFeePerGas") {
+		if b.BaseFeePerGas, err = decodeBigInt(b.BaseFeePerGas, v, "baseFeePerGas"); err != nil {
+			return err
+		}
+	}
+	if b.WithdrawalsRoot, err = decodeOptionalHash(v, "withdrawalsRoot"); err != nil {
+		return err
+	}
+	if b.BlobGasUsed, err = decodeOptionalUint(v, "blobGasUsed"); err != nil {
+		return err
+	}
+	if b.ExcessBlobGas, err = decodeOptionalUint(v, "excessBlobGas"); err != nil {
+		return err
+	}
+	if b.ParentBeaconBlockRoot, err = decodeOptionalHash(v, "parentBeaconBlockRoot"); err != nil {
+		return err
+	}
+	if b.RequestsHash, err = decodeOptionalHash(v, "requestsHash"); err != nil {
+		return err
+	}
+
 	b.TransactionsHashes = b.TransactionsHashes[:0]
 	b.Transactions = b.Transactions[:0]
 
@@ -115,14 +155,26 @@ func (t *Transaction) UnmarshalJSON(buf []byte) error {
 
 func (t *Transaction) unmarshalJSON(v *fastjson.Value) error {
 	var err error
+
+	t.Type = TransactionLegacy
+	if v.Exists("type") {
+		typ, err := decodeUint(v, "type")
+		if err != nil {
+			return err
+		}
+		t.Type = TransactionType(typ)
+	}
+
 	if err := decodeHash(&t.Hash, v, "hash"); err != nil {
 		return err
 	}
 	if err = decodeAddr(&t.From, v, "from"); err != nil {
 		return err
 	}
-	if t.GasPrice, err = decodeUint(v, "gasPrice"); err != nil {
-		return err
+	if !t.Type.hasDynamicFee() || v.Exists("gasPrice") {
+		if t.GasPrice, err = decodeUint(v, "gasPrice"); err != nil {
+			return err
+		}
 	}
 	if t.Gas, err = decodeUint(v, "gas"); err != nil {
 		return err
@@ -167,6 +219,80 @@ func (t *Transaction) unmarshalJSON(v *fastjson.Value) error {
 	if t.TxnIndex, err = decodeUint(v, "transactionIndex"); err != nil {
 		return err
 	}
+
+	if t.Type != TransactionLegacy && v.Exists("chainId") {
+		if t.ChainID, err = decodeBigInt(t.ChainID, v, "chainId"); err != nil {
+			return err
+		}
+	}
+	if t.Type != TransactionLegacy {
+		if err := t.AccessList.unmarshalJSON(v.Get("accessList")); err != nil {
+			return err
+		}
+	}
+	if t.Type.hasDynamicFee() {
+		if t.MaxPriorityFeePerGas, err = decodeBigInt(t.MaxPriorityFeePerGas, v, "maxPriorityFeePerGas"); err != nil {
+			return err
+		}
+		if t.MaxFeePerGas, err = decodeBigInt(t.MaxFeePerGas, v, "maxFeePerGas"); err != nil {
+			return err
+		}
+	}
+	if t.Type == TransactionBlob {
+		if t.MaxFeePerBlobGas, err = decodeBigInt(t.MaxFeePerBlobGas, v, "maxFeePerBlobGas"); err != nil {
+			return err
+		}
+		if !v.Exists("blobVersionedHashes") {
+			return fmt.Errorf("'blobVersionedHashes' not found")
+		}
+		t.BlobVersionedHashes = t.BlobVersionedHashes[:0]
+		for _, elem := range v.GetArray("blobVersionedHashes") {
+			var h Hash
+			if err := h.UnmarshalText(elem.GetStringBytes()); err != nil {
+				return err
+			}
+			t.BlobVersionedHashes = append(t.BlobVersionedHashes, h)
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON implements the unmarshal interface
+func (al *AccessList) UnmarshalJSON(buf []byte) error {
+	p := defaultPool.Get()
+	defer defaultPool.Put(p)
+
+	v, err := p.Parse(string(buf))
+	if err != nil {
+		return err
+	}
+	return al.unmarshalJSON(v)
+}
+
+func (al *AccessList) unmarshalJSON(v *fastjson.Value) error {
+	*al = (*al)[:0]
+	if v == nil || v.Type() == fastjson.TypeNull {
+		return nil
+	}
+
+	elems, err := v.Array()
+	if err != nil {
+		return err
+	}
+	for _, elem := range elems {
+		entry := AccessEntry{}
+		if err := decodeAddr(&entry.Address, elem, "address"); err != nil {
+			return err
+		}
+		for _, key := range elem.GetArray("storageKeys") {
+			var h Hash
+			if err := h.UnmarshalText(key.GetStringBytes()); err != nil {
+				return err
+			}
+			entry.Storage = append(entry.Storage, h)
+		}
+		*al = append(*al, entry)
+	}
 	return nil
 }
 
@@ -180,6 +306,24 @@ func (r *Receipt) UnmarshalJSON(buf []byte) error {
 		return nil
 	}
 
+	r.Type = TransactionLegacy
+	if v.Exists("type") {
+		typ, err := decodeUint(v, "type")
+		if err != nil {
+			return err
+		}
+		r.Type = TransactionType(typ)
+	}
+	// receipts before byzantium include the state root instead of the status
+	if fieldNotFull(v, "status") {
+		if r.Status, err = decodeUint(v, "status"); err != nil {
+			return err
+		}
+	}
+	if r.Root, err = decodeOptionalHash(v, "root"); err != nil {
+		return err
+	}
+
 	if err := decodeAddr(&r.From, v, "from"); err != nil {
 		return err
 	}
@@ -223,6 +367,141 @@ func (r *Receipt) UnmarshalJSON(buf []byte) error {
 	return nil
 }
 
+// UnmarshalJSON implements the unmarshal interface
+func (a *AccountProof) UnmarshalJSON(buf []byte) error {
+	p := defaultPool.Get()
+	defer defaultPool.Put(p)
+
+	v, err := p.Parse(string(buf))
+	if err != nil {
+		return err
+	}
+
+	if err := decodeAddr(&a.Address, v, "address"); err != nil {
+		return err
+	}
+	if a.AccountProof, err = decodeProof(v, "accountProof"); err != nil {
+		return err
+	}
+	if a.Balance, err = decodeBigInt(a.Balance, v, "balance"); err != nil {
+		return err
+	}
+	if err := decodeHash(&a.CodeHash, v, "codeHash"); err != nil {
+		return err
+	}
+	if a.Nonce, err = decodeUint(v, "nonce"); err != nil {
+		return err
+	}
+	if err := decodeHash(&a.StorageHash, v, "storageHash"); err != nil {
+		return err
+	}
+
+	a.StorageProof = a.StorageProof[:0]
+	for _, elem := range v.GetArray("storageProof") {
+		proof := new(StorageProof)
+
+		// the key is returned as it was requested and may not be padded
+		key, err := decodeBytes(nil, elem, "key")
+		if err != nil {
+			return err
+		}
+		if len(key) > 32 {
+			return fmt.Errorf("storage key too long: %d bytes", len(key))
+		}
+		proof.Key = BytesToHash(key)
+
+		if proof.Value, err = decodeBigInt(proof.Value, elem, "value"); err != nil {
+			return err
+		}
+		if proof.Proof, err = decodeProof(elem, "proof"); err != nil {
+			return err
+		}
+		a.StorageProof = append(a.StorageProof, proof)
+	}
+	return nil
+}
+
+// UnmarshalJSON implements the unmarshal interface
+func (f *FeeHistory) UnmarshalJSON(buf []byte) error {
+	p := defaultPool.Get()
+	defer defaultPool.Put(p)
+
+	v, err := p.Parse(string(buf))
+	if err != nil {
+		return err
+	}
+
+	if f.OldestBlock, err = decodeUint(v, "oldestBlock"); err != nil {
+		return err
+	}
+	if f.BaseFeePerGas, err = decodeBigIntArray(v.GetArray("baseFeePerGas")); err != nil {
+		return err
+	}
+
+	f.GasUsedRatio = f.GasUsedRatio[:0]
+	for _, elem := range v.GetArray("gasUsedRatio") {
+		ratio, err := elem.Float64()
+		if err != nil {
+			return err
+		}
+		f.GasUsedRatio = append(f.GasUsedRatio, ratio)
+	}
+
+	// the reward is only returned if percentiles are requested
+	f.Reward = f.Reward[:0]
+	for _, elem := range v.GetArray("reward") {
+		reward, err := decodeBigIntArray(elem.GetArray())
+		if err != nil {
+			return err
+		}
+		f.Reward = append(f.Reward, reward)
+	}
+	return nil
+}
+
+func decodeBigIntArray(elems []*fastjson.Value) ([]*big.Int, error) {
+	res := []*big.Int{}
+	for _, elem := range elems {
+		b, err := elem.StringBytes()
+		if err != nil {
+			return nil, err
+		}
+		str := string(b)
+		if !strings.HasPrefix(str, "0x") {
+			return nil, fmt.Errorf("value does not have 0x prefix: '%s'", str)
+		}
+		num, ok := new(big.Int).SetString(str[2:], 16)
+		if !ok {
+			return nil, fmt.Errorf("failed to decode big int: '%s'", str)
+		}
+		res = append(res, num)
+	}
+	return res, nil
+}
+
+func decodeProof(v *fastjson.Value, key string) ([][]byte, error) {
+	if !v.Exists(key) {
+		return nil, fmt.Errorf("field '%s' not found", key)
+	}
+	res := [][]byte{}
+	for _, elem := range v.GetArray(key) {
+		b, err := elem.StringBytes()
+		if err != nil {
+			return nil, err
+		}
+		str := string(b)
+		if !strings.HasPrefix(str, "0x") {
+			return nil, fmt.Errorf("field '%s' does not have 0x prefix: '%s'", key, str)
+		}
+		node, err := hex.DecodeString(str[2:])
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, node)
+	}
+	return res, nil
+}
+
 // UnmarshalJSON implements the unmarshal interface
 func (r *Log) UnmarshalJSON(buf []byte) error {
 	p := defaultPool.Get()
@@ -351,6 +630,28 @@ func decodeUint(v *fastjson.Value, key string) (uint64, error) {
 	return strconv.ParseUint(str[2:], 16, 64)
 }
 
+func decodeOptionalUint(v *fastjson.Value, key string) (*uint64, error) {
+	if !fieldNotFull(v, key) {
+		return nil, nil
+	}
+	num, err := decodeUint(v, key)
+	if err != nil {
+		return nil, err
+	}
+	return &num, nil
+}
+
+func decodeOptionalHash(v *fastjson.Value, key string) (*Hash, error) {
+	if !fieldNotFull(v, key) {
+		return nil, nil
+	}
+	h := new(Hash)
+	if err := decodeHash(h, v, key); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
 func decodeHash(h *Hash, v *fastjson.Value, key string) error {
 	b := v.GetStringBytes(key)
 	if len(b) == 0 {
@@ -0,0 +1,208 @@
+package web3
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/mover-code/golang-web3/fastrlp"
+)
+
+// UnmarshalRLP unmarshals a raw signed transaction in either the legacy
+// or the typed (EIP-2718) envelope. The hash of the transaction is
+// computed from the raw bytes.
+func (t *Transaction) UnmarshalRLP(buf []byte) error {
+	if len(buf) == 0 {
+		return fmt.Errorf("empty transaction")
+	}
+
+	t.Type = TransactionLegacy
+	payload := buf
+	if buf[0] <= 0x7f {
+		// typed transaction
+		t.Type = TransactionType(buf[0])
+		if t.Type != TransactionAccessList && t.Type != TransactionDynamicFee && t.Type != TransactionBlob {
+			return fmt.Errorf("transaction type %d not supported", buf[0])
+		}
+		payload = buf[1:]
+	}
+
+	p := fastrlp.DefaultParserPool.Get()
+	defer fastrlp.DefaultParserPool.Put(p)
+
+	v, err := p.Parse(payload)
+	if err != nil {
+		return err
+	}
+	if err := t.UnmarshalRLPWith(v); err != nil {
+		return err
+	}
+
+	t.Hash = BytesToHash(Keccak256(buf))
+	return nil
+}
+
+// UnmarshalRLPWith unmarshals the transaction payload from a fastrlp.Value.
+// The type of the transaction has to be set beforehand.
+func (t *Transaction) UnmarshalRLPWith(v *fastrlp.Value) error {
+	elems, err := v.GetElems()
+	if err != nil {
+		return err
+	}
+
+	num := 9
+	switch t.Type {
+	case TransactionAccessList:
+		num = 11
+	case TransactionDynamicFee:
+		num = 12
+	case TransactionBlob:
+		num = 14
+	}
+	if len(elems) != num {
+		return fmt.Errorf("incorrect number of elements to decode transaction, expected %d but found %d", num, len(elems))
+	}
+
+	getElem := func() *fastrlp.Value {
+		v := elems[0]
+		elems = elems[1:]
+		return v
+	}
+
+	if t.Type != TransactionLegacy {
+		t.ChainID = new(big.Int)
+		if err := getElem().GetBigInt(t.ChainID); err != nil {
+			return err
+		}
+	}
+	if t.Nonce, err = getElem().GetUint64(); err != nil {
+		return err
+	}
+	if t.Type.hasDynamicFee() {
+		t.MaxPriorityFeePerGas = new(big.Int)
+		if err := getElem().GetBigInt(t.MaxPriorityFeePerGas); err != nil {
+			return err
+		}
+		t.MaxFeePerGas = new(big.Int)
+		if err := getElem().GetBigInt(t.MaxFeePerGas); err != nil {
+			return err
+		}
+	} else {
+		if t.GasPrice, err = getElem().GetUint64(); err != nil {
+			return err
+		}
+	}
+	if t.Gas, err = getElem().GetUint64(); err != nil {
+		return err
+	}
+
+	// Address may be empty
+	to, err := getElem().Bytes()
+	if err != nil {
+		return err
+	}
+	if len(to) == 0 {
+		t.To = nil
+	} else {
+		if len(to) != 20 {
+			return fmt.Errorf("bad 'to' length, expected 20 but found %d", len(to))
+		}
+		addr := BytesToAddress(to)
+		t.To = &addr
+	}
+
+	t.Value = new(big.Int)
+	if err := getElem().GetBigInt(t.Value); err != nil {
+		return err
+	}
+	if t.Input, err = getElem().GetBytes(t.Input[:0]); err != nil {
+		return err
+	}
+
+	if t.Type != TransactionLegacy {
+		if err := t.AccessList.UnmarshalRLPWith(getElem()); err != nil {
+			return err
+		}
+	}
+	if t.Type == TransactionBlob {
+		t.MaxFeePerBlobGas = new(big.Int)
+		if err := getElem().GetBigInt(t.MaxFeePerBlobGas); err != nil {
+			return err
+		}
+		if t.BlobVersionedHashes, err = unmarshalHashes(t.BlobVersionedHashes[:0], getElem()); err != nil {
+			return err
+		}
+	}
+
+	// signature values
+	if t.V, err = getElem().GetBytes(t.V[:0]); err != nil {
+		return err
+	}
+	if t.R, err = getElem().GetBytes(t.R[:0]); err != nil {
+		return err
+	}
+	if t.S, err = getElem().GetBytes(t.S[:0]); err != nil {
+		return err
+	}
+	return nil
+}
+
+func unmarshalHashes(dst []Hash, v *fastrlp.Value) ([]Hash, error) {
+	if v.Type() == fastrlp.TypeArrayNull {
+		return dst, nil
+	}
+	elems, err := v.GetElems()
+	if err != nil {
+		return nil, err
+	}
+	for _, elem := range elems {
+		var h Hash
+		if err := elem.GetHash(h[:]); err != nil {
+			return nil, err
+		}
+		dst = append(dst, h)
+	}
+	return dst, nil
+}
+
+// UnmarshalRLPWith unmarshals the access list from a fastrlp.Value
+func (a *AccessList) UnmarshalRLPWith(v *fastrlp.Value) error {
+	*a = (*a)[:0]
+	if v.Type() == fastrlp.TypeArrayNull {
+		return nil
+	}
+
+	elems, err := v.GetElems()
+	if err != nil {
+		return err
+	}
+	for _, elem := range elems {
+		vv, err := elem.GetElems()
+		if err != nil {
+			return err
+		}
+		if len(vv) != 2 {
+			return fmt.Errorf("incorrect number of elements to decode access entry, expected 2 but found %d", len(vv))
+		}
+
+		entry := AccessEntry{}
+		if err := vv[0].GetAddr(entry.Address[:]); err != nil {
+			return err
+		}
+
+		if vv[1].Type() != fastrlp.TypeArrayNull {
+			keys, err := vv[1].GetElems()
+			if err != nil {
+				return err
+			}
+			for _, key := range keys {
+				var h Hash
+				if err := key.GetHash(h[:]); err != nil {
+					return err
+				}
+				entry.Storage = append(entry.Storage, h)
+			}
+		}
+		*a = append(*a, entry)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package trie
+
+import (
+	"fmt"
+
+	web3 "github.com/mover-code/golang-web3"
+	"github.com/mover-code/golang-web3/fastrlp"
+)
+
+// DeriveRoot computes the root of the trie that stores each item of a list
+// keyed by the RLP encoding of its index, as in the transactions
+// and receipts tries of a block.
+func DeriveRoot(num int, item func(i int) []byte) web3.Hash {
+	a := fastrlp.DefaultArenaPool.Get()
+	defer fastrlp.DefaultArenaPool.Put(a)
+
+	t := NewTrie()
+	for i := 0; i < num; i++ {
+		key := a.NewUint(uint64(i)).MarshalTo(nil)
+		t.Insert(key, item(i))
+	}
+	return t.Hash()
+}
+
+// TransactionsRoot computes the transactions root of a block from its
+// full list of transactions. It fails if any transaction has a type whose
+// encoding is not supported.
+func TransactionsRoot(txns []*web3.Transaction) (web3.Hash, error) {
+	for i, txn := range txns {
+		switch txn.Type {
+		case web3.TransactionLegacy, web3.TransactionAccessList, web3.TransactionDynamicFee, web3.TransactionBlob:
+		default:
+			return web3.Hash{}, fmt.Errorf("transaction %d has unsupported type %d", i, txn.Type)
+		}
+	}
+	root := DeriveRoot(len(txns), func(i int) []byte {
+		return txns[i].MarshalRLP()
+	})
+	return root, nil
+}
+
+// ReceiptsRoot computes the receipts root of a block from the
+// receipts of its transactions in order.
+func ReceiptsRoot(receipts []*web3.Receipt) web3.Hash {
+	return DeriveRoot(len(receipts), func(i int) []byte {
+		return receipts[i].MarshalRLP()
+	})
+}
@@ -0,0 +1,56 @@
+package trie
+
+// Keys in the trie are handled as nibbles (hex encoding) with an optional
+// terminator flag (16) at the end for the leaf nodes. When stored in the
+// nodes they are encoded in the compact (hex-prefix) encoding.
+
+const terminator = 16
+
+func keybytesToHex(str []byte) []byte {
+	l := len(str)*2 + 1
+	nibbles := make([]byte, l)
+	for i, b := range str {
+		nibbles[i*2] = b / 16
+		nibbles[i*2+1] = b % 16
+	}
+	nibbles[l-1] = terminator
+	return nibbles
+}
+
+func hasTerm(s []byte) bool {
+	return len(s) > 0 && s[len(s)-1] == terminator
+}
+
+func hexToCompact(hex []byte) []byte {
+	flag := byte(0)
+	if hasTerm(hex) {
+		flag = 1
+		hex = hex[:len(hex)-1]
+	}
+	buf := make([]byte, len(hex)/2+1)
+	buf[0] = flag << 5
+	if len(hex)&1 == 1 {
+		// odd flag and first nibble
+		buf[0] |= 1 << 4
+		buf[0] |= hex[0]
+		hex = hex[1:]
+	}
+	for bi, ni := 0, 0; ni < len(hex); bi, ni = bi+1, ni+2 {
+		buf[bi+1] = hex[ni]<<4 | hex[ni+1]
+	}
+	return buf
+}
+
+func compactToHex(compact []byte) []byte {
+	if len(compact) == 0 {
+		return compact
+	}
+	base := keybytesToHex(compact)
+	// delete the terminator if it is not a leaf
+	if base[0] < 2 {
+		base = base[:len(base)-1]
+	}
+	// skip the flag nibble and the padding nibble if the length is even
+	chop := 2 - base[0]&1
+	return base[chop:]
+}
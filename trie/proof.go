@@ -0,0 +1,190 @@
+package trie
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	web3 "github.com/mover-code/golang-web3"
+	"github.com/mover-code/golang-web3/fastrlp"
+)
+
+var emptyCodeHash = web3.BytesToHash(web3.Keccak256(nil))
+
+// VerifyProof checks the merkle proof of a key against the root of the trie.
+// It returns the value of the key or nil if the proof shows that
+// the key is not in the trie.
+func VerifyProof(root web3.Hash, key []byte, proof [][]byte) ([]byte, error) {
+	if root == EmptyRoot {
+		// the trie is empty and the proof has no nodes
+		return nil, nil
+	}
+	nodes := map[web3.Hash][]byte{}
+	for _, node := range proof {
+		nodes[web3.BytesToHash(web3.Keccak256(node))] = node
+	}
+
+	p := fastrlp.DefaultParserPool.Get()
+	defer fastrlp.DefaultParserPool.Put(p)
+
+	resolve := func(hash web3.Hash) (*fastrlp.Value, error) {
+		buf, ok := nodes[hash]
+		if !ok {
+			return nil, fmt.Errorf("proof node %s not found", hash)
+		}
+		return p.Parse(buf)
+	}
+
+	n, err := resolve(root)
+	if err != nil {
+		return nil, err
+	}
+
+	key = keybytesToHex(key)
+	for {
+		elems, err := n.GetElems()
+		if err != nil {
+			return nil, err
+		}
+
+		var child *fastrlp.Value
+		switch len(elems) {
+		case 17:
+			child, key = elems[key[0]], key[1:]
+			if len(key) == 0 {
+				// value stored in the branch
+				return copyValue(child)
+			}
+
+		case 2:
+			compact, err := elems[0].Bytes()
+			if err != nil {
+				return nil, err
+			}
+			nibbles := compactToHex(compact)
+			if len(key) < len(nibbles) || !bytes.Equal(nibbles, key[:len(nibbles)]) {
+				// the path diverges, the key is not in the trie
+				return nil, nil
+			}
+			child, key = elems[1], key[len(nibbles):]
+			if hasTerm(nibbles) {
+				return copyValue(child)
+			}
+
+		default:
+			return nil, fmt.Errorf("invalid trie node with %d elements", len(elems))
+		}
+
+		if child.Type() == fastrlp.TypeArray {
+			// embedded node
+			n = child
+			continue
+		}
+		ref, err := child.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		if len(ref) == 0 {
+			// empty branch, the key is not in the trie
+			return nil, nil
+		}
+		if len(ref) != 32 {
+			return nil, fmt.Errorf("invalid node reference length %d", len(ref))
+		}
+		if n, err = resolve(web3.BytesToHash(ref)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func copyValue(v *fastrlp.Value) ([]byte, error) {
+	buf, err := v.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) == 0 {
+		return nil, nil
+	}
+	return append([]byte{}, buf...), nil
+}
+
+// VerifyAccountProof checks the account and storage proofs returned by
+// eth_getProof against the state root of a block.
+func VerifyAccountProof(stateRoot web3.Hash, proof *web3.AccountProof) error {
+	val, err := VerifyProof(stateRoot, web3.Keccak256(proof.Address[:]), proof.AccountProof)
+	if err != nil {
+		return fmt.Errorf("account proof: %v", err)
+	}
+
+	balance := proof.Balance
+	if balance == nil {
+		balance = new(big.Int)
+	}
+
+	if val == nil {
+		// the account does not exist
+		if proof.Nonce != 0 || balance.Sign() != 0 ||
+			(proof.CodeHash != web3.ZeroHash && proof.CodeHash != emptyCodeHash) ||
+			(proof.StorageHash != web3.ZeroHash && proof.StorageHash != EmptyRoot) {
+			return fmt.Errorf("account %s is not in the state", proof.Address)
+		}
+	} else {
+		a := fastrlp.DefaultArenaPool.Get()
+		v := a.NewArray()
+		v.Set(a.NewUint(proof.Nonce))
+		v.Set(a.NewBigInt(balance))
+		v.Set(a.NewCopyBytes(proof.StorageHash[:]))
+		v.Set(a.NewCopyBytes(proof.CodeHash[:]))
+		expected := v.MarshalTo(nil)
+		fastrlp.DefaultArenaPool.Put(a)
+
+		if !bytes.Equal(val, expected) {
+			return fmt.Errorf("account %s does not match the proof", proof.Address)
+		}
+	}
+
+	for _, storage := range proof.StorageProof {
+		if err := VerifyStorageProof(proof.StorageHash, storage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyStorageProof checks the proof of a storage slot against
+// the storage root of the account.
+func VerifyStorageProof(storageRoot web3.Hash, proof *web3.StorageProof) error {
+	val, err := VerifyProof(storageRoot, web3.Keccak256(proof.Key[:]), proof.Proof)
+	if err != nil {
+		return fmt.Errorf("storage proof %s: %v", proof.Key, err)
+	}
+
+	value := proof.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	if val == nil {
+		if value.Sign() != 0 {
+			return fmt.Errorf("storage slot %s is not in the state", proof.Key)
+		}
+		return nil
+	}
+
+	// the values are stored as rlp encoded integers
+	p := fastrlp.DefaultParserPool.Get()
+	defer fastrlp.DefaultParserPool.Put(p)
+
+	v, err := p.Parse(val)
+	if err != nil {
+		return err
+	}
+	found := new(big.Int)
+	if err := v.GetBigInt(found); err != nil {
+		return err
+	}
+	if found.Cmp(value) != 0 {
+		return fmt.Errorf("storage slot %s does not match the proof", proof.Key)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package trie
+
+import (
+	"math/big"
+	mrand "math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	gethtrie "github.com/ethereum/go-ethereum/trie"
+	web3 "github.com/mover-code/golang-web3"
+	"github.com/stretchr/testify/assert"
+)
+
+func newGethTrie() *gethtrie.Trie {
+	return gethtrie.NewEmpty(gethtrie.NewDatabase(rawdb.NewMemoryDatabase()))
+}
+
+func prove(t *testing.T, tt *gethtrie.Trie, key []byte) [][]byte {
+	db := memorydb.New()
+	assert.NoError(t, tt.Prove(key, 0, db))
+
+	proof := [][]byte{}
+	it := db.NewIterator(nil, nil)
+	for it.Next() {
+		proof = append(proof, common.CopyBytes(it.Value()))
+	}
+	it.Release()
+	return proof
+}
+
+func TestProof_VerifyProof(t *testing.T) {
+	tt := newGethTrie()
+
+	keys := [][]byte{}
+	for i := 0; i < 500; i++ {
+		key := randomBytes(mrand.Intn(8) + 1)
+		val := randomBytes(mrand.Intn(40) + 1)
+
+		tt.Update(key, val)
+		keys = append(keys, key)
+	}
+	root := web3.Hash(tt.Hash())
+
+	for _, key := range keys {
+		val, err := VerifyProof(root, key, prove(t, tt, key))
+		assert.NoError(t, err)
+		assert.Equal(t, val, tt.Get(key))
+	}
+
+	// proof of absence
+	missing := randomBytes(10)
+	val, err := VerifyProof(root, missing, prove(t, tt, missing))
+	assert.NoError(t, err)
+	assert.Nil(t, val)
+
+	// a proof for another root is not valid
+	_, err = VerifyProof(web3.Hash{0x1}, keys[0], prove(t, tt, keys[0]))
+	assert.Error(t, err)
+}
+
+func TestProof_VerifyAccountProof(t *testing.T) {
+	addr := web3.Address{0x1}
+	slot := web3.Hash{0x2}
+	slotValue := big.NewInt(1000)
+	otherSlot := web3.Hash{0x4}
+	emptySlot := web3.Hash{0x3}
+
+	// storage trie with two slots
+	storage := newGethTrie()
+	slotKey := crypto.Keccak256(slot[:])
+	enc, _ := rlp.EncodeToBytes(slotValue)
+	storage.Update(slotKey, enc)
+	enc, _ = rlp.EncodeToBytes(big.NewInt(2000))
+	storage.Update(crypto.Keccak256(otherSlot[:]), enc)
+
+	account := &types.StateAccount{
+		Nonce:    5,
+		Balance:  big.NewInt(10000),
+		Root:     storage.Hash(),
+		CodeHash: crypto.Keccak256([]byte{0x60}),
+	}
+	state := newGethTrie()
+	accountKey := crypto.Keccak256(addr[:])
+	enc, _ = rlp.EncodeToBytes(account)
+	state.Update(accountKey, enc)
+
+	// some other accounts in the state
+	for i := 0; i < 50; i++ {
+		state.Update(crypto.Keccak256(randomBytes(20)), randomBytes(70))
+	}
+	stateRoot := web3.Hash(state.Hash())
+
+	proof := &web3.AccountProof{
+		Address:      addr,
+		AccountProof: prove(t, state, accountKey),
+		Balance:      account.Balance,
+		CodeHash:     web3.BytesToHash(account.CodeHash),
+		Nonce:        account.Nonce,
+		StorageHash:  web3.Hash(account.Root),
+		StorageProof: []*web3.StorageProof{
+			{
+				Key:   slot,
+				Value: slotValue,
+				Proof: prove(t, storage, slotKey),
+			},
+			{
+				// empty slot
+				Key:   emptySlot,
+				Value: big.NewInt(0),
+				Proof: prove(t, storage, crypto.Keccak256(emptySlot[:])),
+			},
+		},
+	}
+	assert.NoError(t, VerifyAccountProof(stateRoot, proof))
+
+	// wrong balance
+	proof.Balance = big.NewInt(1)
+	assert.Error(t, VerifyAccountProof(stateRoot, proof))
+	proof.Balance = account.Balance
+
+	// wrong storage value
+	proof.StorageProof[0].Value = big.NewInt(1)
+	assert.Error(t, VerifyAccountProof(stateRoot, proof))
+	proof.StorageProof[0].Value = slotValue
+
+	// the proof is for another slot
+	proof.StorageProof[0].Key = otherSlot
+	assert.Error(t, VerifyAccountProof(stateRoot, proof))
+	proof.StorageProof[0].Key = slot
+
+	// account that does not exist
+	missing := web3.Address{0x9}
+	missingProof := &web3.AccountProof{
+		Address:      missing,
+		AccountProof: prove(t, state, crypto.Keccak256(missing[:])),
+		Balance:      big.NewInt(0),
+	}
+	assert.NoError(t, VerifyAccountProof(stateRoot, missingProof))
+
+	missingProof.Balance = big.NewInt(1)
+	assert.Error(t, VerifyAccountProof(stateRoot, missingProof))
+}
+
+func TestProof_VerifyAccountProofEOA(t *testing.T) {
+	addr := web3.Address{0x1}
+	emptySlot := web3.Hash{0x3}
+
+	// the account has no code nor storage
+	storage := newGethTrie()
+	assert.Equal(t, web3.Hash(storage.Hash()), EmptyRoot)
+
+	account := &types.StateAccount{
+		Nonce:    1,
+		Balance:  big.NewInt(10000),
+		Root:     storage.Hash(),
+		CodeHash: crypto.Keccak256(nil),
+	}
+	state := newGethTrie()
+	accountKey := crypto.Keccak256(addr[:])
+	enc, _ := rlp.EncodeToBytes(account)
+	state.Update(accountKey, enc)
+	stateRoot := web3.Hash(state.Hash())
+
+	emptyProof := prove(t, storage, crypto.Keccak256(emptySlot[:]))
+	assert.Empty(t, emptyProof)
+
+	proof := &web3.AccountProof{
+		Address:      addr,
+		AccountProof: prove(t, state, accountKey),
+		Balance:      account.Balance,
+		CodeHash:     web3.BytesToHash(account.CodeHash),
+		Nonce:        account.Nonce,
+		StorageHash:  EmptyRoot,
+		StorageProof: []*web3.StorageProof{
+			{
+				Key:   emptySlot,
+				Value: big.NewInt(0),
+				Proof: emptyProof,
+			},
+		},
+	}
+	assert.NoError(t, VerifyAccountProof(stateRoot, proof))
+
+	// the slots of an empty storage have no value
+	proof.StorageProof[0].Value = big.NewInt(1)
+	assert.Error(t, VerifyAccountProof(stateRoot, proof))
+}
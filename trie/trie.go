@@ -0,0 +1,153 @@
+package trie
+
+import (
+	web3 "github.com/mover-code/golang-web3"
+	"github.com/mover-code/golang-web3/fastrlp"
+)
+
+// EmptyRoot is the root of an empty trie
+var EmptyRoot = web3.HexToHash("0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+
+type node interface{}
+
+type (
+	// fullNode is a branch node, the 17th child holds the value
+	fullNode struct {
+		children [17]node
+	}
+
+	// shortNode is either an extension or a leaf node if the key
+	// ends with the terminator nibble
+	shortNode struct {
+		key []byte
+		val node
+	}
+
+	valueNode []byte
+)
+
+// Trie is an in-memory Merkle Patricia Trie
+type Trie struct {
+	root node
+}
+
+// NewTrie creates an empty trie
+func NewTrie() *Trie {
+	return &Trie{}
+}
+
+// Insert sets the value for the key. Empty values are not allowed in the trie.
+func (t *Trie) Insert(key, value []byte) {
+	t.root = insert(t.root, keybytesToHex(key), valueNode(value))
+}
+
+// Hash returns the root hash of the trie
+func (t *Trie) Hash() web3.Hash {
+	if t.root == nil {
+		return EmptyRoot
+	}
+
+	a := fastrlp.DefaultArenaPool.Get()
+	defer fastrlp.DefaultArenaPool.Put(a)
+
+	h := &hasher{arena: a}
+	buf := h.encode(t.root).MarshalTo(nil)
+	return web3.BytesToHash(web3.Keccak256(buf))
+}
+
+func insert(n node, key []byte, value node) node {
+	if len(key) == 0 {
+		return value
+	}
+
+	switch n := n.(type) {
+	case nil:
+		return &shortNode{key: key, val: value}
+
+	case *shortNode:
+		match := prefixLen(key, n.key)
+		if match == len(n.key) {
+			n.val = insert(n.val, key[match:], value)
+			return n
+		}
+
+		// the keys diverge, split the node with a branch at the
+		// first different nibble
+		branch := &fullNode{}
+		branch.children[n.key[match]] = insert(nil, n.key[match+1:], n.val)
+		branch.children[key[match]] = insert(nil, key[match+1:], value)
+		if match == 0 {
+			return branch
+		}
+		return &shortNode{key: key[:match], val: branch}
+
+	case *fullNode:
+		n.children[key[0]] = insert(n.children[key[0]], key[1:], value)
+		return n
+
+	default:
+		panic("BUG: unexpected trie node")
+	}
+}
+
+type hasher struct {
+	arena *fastrlp.Arena
+}
+
+// encode returns the rlp value of the node with its children
+// either embedded or referenced by hash
+func (h *hasher) encode(n node) *fastrlp.Value {
+	a := h.arena
+
+	switch n := n.(type) {
+	case *shortNode:
+		v := a.NewArray()
+		v.Set(a.NewBytes(hexToCompact(n.key)))
+		v.Set(h.ref(n.val))
+		return v
+
+	case *fullNode:
+		v := a.NewArray()
+		for _, child := range n.children {
+			if child == nil {
+				v.Set(a.NewNull())
+			} else {
+				v.Set(h.ref(child))
+			}
+		}
+		return v
+
+	case valueNode:
+		return a.NewCopyBytes(n)
+
+	default:
+		panic("BUG: unexpected trie node")
+	}
+}
+
+// ref returns the reference of a child node. Nodes whose encoding
+// is shorter than 32 bytes are embedded in the parent.
+func (h *hasher) ref(n node) *fastrlp.Value {
+	v := h.encode(n)
+	if _, ok := n.(valueNode); ok {
+		return v
+	}
+	buf := v.MarshalTo(nil)
+	if len(buf) < 32 {
+		return v
+	}
+	return h.arena.NewCopyBytes(web3.Keccak256(buf))
+}
+
+func prefixLen(a, b []byte) int {
+	i, length := 0, len(a)
+	if len(b) < length {
+		length = len(b)
+	}
+	for ; i < length; i++ {
+		if a[i] != b[i] {
+			break
+		}
+	}
+	return i
+}
@@ -0,0 +1,122 @@
+package trie
+
+import (
+	"crypto/rand"
+	"math/big"
+	mrand "math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	gethtrie "github.com/ethereum/go-ethereum/trie"
+	web3 "github.com/mover-code/golang-web3"
+	"github.com/stretchr/testify/assert"
+)
+
+func randomBytes(n int) []byte {
+	buf := make([]byte, n)
+	rand.Read(buf)
+	return buf
+}
+
+func TestTrie_Empty(t *testing.T) {
+	assert.Equal(t, NewTrie().Hash(), EmptyRoot)
+	assert.Equal(t, DeriveRoot(0, nil), EmptyRoot)
+}
+
+func TestTrie_Random(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		tt := NewTrie()
+		expected := gethtrie.NewEmpty(gethtrie.NewDatabase(rawdb.NewMemoryDatabase()))
+
+		for j := 0; j < mrand.Intn(50)+1; j++ {
+			// short keys force shared prefixes, short values embedded nodes
+			key := randomBytes(mrand.Intn(4) + 1)
+			val := randomBytes(mrand.Intn(40) + 1)
+
+			tt.Insert(key, val)
+			expected.Update(key, val)
+		}
+		assert.Equal(t, tt.Hash().String(), expected.Hash().String())
+	}
+}
+
+func TestTrie_TransactionsRoot(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := types.NewLondonSigner(big.NewInt(1))
+	to := common.Address{0x1}
+
+	var gethTxns types.Transactions
+	var txns []*web3.Transaction
+
+	for i := 0; i < 200; i++ {
+		var data types.TxData
+		switch i % 3 {
+		case 0:
+			data = &types.LegacyTx{Nonce: uint64(i), GasPrice: big.NewInt(1), Gas: 21000, To: &to, Value: big.NewInt(1)}
+		case 1:
+			data = &types.AccessListTx{ChainID: big.NewInt(1), Nonce: uint64(i), GasPrice: big.NewInt(1), Gas: 21000, Value: big.NewInt(0)}
+		case 2:
+			data = &types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: uint64(i), GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Gas: 21000, To: &to, Value: big.NewInt(0), Data: randomBytes(100)}
+		}
+		gethTxn := types.MustSignNewTx(key, signer, data)
+		gethTxns = append(gethTxns, gethTxn)
+
+		raw, err := gethTxn.MarshalBinary()
+		assert.NoError(t, err)
+
+		txn := new(web3.Transaction)
+		assert.NoError(t, txn.UnmarshalRLP(raw))
+		txns = append(txns, txn)
+
+		expected := types.DeriveSha(gethTxns, gethtrie.NewStackTrie(nil))
+		root, err := TransactionsRoot(txns)
+		assert.NoError(t, err)
+		assert.Equal(t, root.String(), expected.String())
+	}
+
+	// the encoding of the type is not known
+	txns = append(txns, &web3.Transaction{Type: 4})
+	_, err := TransactionsRoot(txns)
+	assert.Error(t, err)
+}
+
+func TestTrie_ReceiptsRoot(t *testing.T) {
+	var gethReceipts types.Receipts
+	var receipts []*web3.Receipt
+
+	for i := 0; i < 20; i++ {
+		log := &types.Log{
+			Address: common.Address{byte(i)},
+			Topics:  []common.Hash{{0x1}, {byte(i)}},
+			Data:    randomBytes(i),
+		}
+		gethReceipt := &types.Receipt{
+			Type:              uint8(i % 3),
+			Status:            uint64(i % 2),
+			CumulativeGasUsed: uint64(21000 * (i + 1)),
+			Logs:              []*types.Log{log},
+		}
+		gethReceipt.Bloom = types.CreateBloom(types.Receipts{gethReceipt})
+		gethReceipts = append(gethReceipts, gethReceipt)
+
+		receipts = append(receipts, &web3.Receipt{
+			Type:              web3.TransactionType(gethReceipt.Type),
+			Status:            gethReceipt.Status,
+			CumulativeGasUsed: gethReceipt.CumulativeGasUsed,
+			LogsBloom:         gethReceipt.Bloom.Bytes(),
+			Logs: []*web3.Log{
+				{
+					Address: web3.Address(log.Address),
+					Topics:  []web3.Hash{web3.Hash(log.Topics[0]), web3.Hash(log.Topics[1])},
+					Data:    log.Data,
+				},
+			},
+		})
+
+		expected := types.DeriveSha(gethReceipts, gethtrie.NewStackTrie(nil))
+		assert.Equal(t, ReceiptsRoot(receipts).String(), expected.String())
+	}
+}
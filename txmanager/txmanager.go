@@ -0,0 +1,441 @@
+package txmanager
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	web3 "github.com/mover-code/golang-web3"
+	"github.com/mover-code/golang-web3/contract"
+	"github.com/mover-code/golang-web3/nonce"
+	"github.com/mover-code/golang-web3/wallet"
+)
+
+const (
+	defaultPollInterval  = 1 * time.Second
+	defaultResendTimeout = 1 * time.Minute
+	defaultBumpPercent   = 10
+
+	defaultMaxResendFailures = 3
+
+	// minBumpPercent is the minimum fee bump required by the nodes
+	// to replace a transaction in the pool
+	minBumpPercent = 10
+
+	transferGas = 21000
+)
+
+var (
+	// ErrNonceUsed happens when a transaction that is not tracked
+	// is mined with the nonce of the pending transaction
+	ErrNonceUsed = errors.New("nonce used by another transaction")
+
+	// ErrClosed happens when the manager is closed before
+	// the transaction is mined
+	ErrClosed = errors.New("transaction manager closed")
+)
+
+// Provider are the eth1x methods required by the transaction manager
+type Provider interface {
+	SendRawTransaction(data []byte) (web3.Hash, error)
+	GetTransactionReceipt(hash web3.Hash) (*web3.Receipt, error)
+	GetNonce(addr web3.Address, blockNumber web3.BlockNumberOrHash) (uint64, error)
+}
+
+// Config is the configuration of the transaction manager
+type Config struct {
+	// PollInterval is the interval to query the receipts
+	PollInterval time.Duration
+
+	// ResendTimeout is the time to wait for a transaction to be
+	// mined before it is sent again with higher fees
+	ResendTimeout time.Duration
+
+	// BumpPercent is the increase of the fees on each resend
+	BumpPercent uint64
+
+	// MaxFeePerGas is the limit for the gas price or the max fee per
+	// gas of the resends. There is no limit if it is nil.
+	MaxFeePerGas *big.Int
+
+	// MaxResendFailures is the number of consecutive resends that can
+	// fail before the tracking stops with the error of the last one
+	MaxResendFailures uint64
+}
+
+type ConfigOption func(*Config)
+
+func WithPollInterval(d time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.PollInterval = d
+	}
+}
+
+func WithResendTimeout(d time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.ResendTimeout = d
+	}
+}
+
+// WithBumpPercent sets the increase of the fees on each resend. Values
+// below the 10% required by the nodes to replace a transaction are raised.
+func WithBumpPercent(p uint64) ConfigOption {
+	return func(c *Config) {
+		c.BumpPercent = p
+	}
+}
+
+func WithMaxFeePerGas(f *big.Int) ConfigOption {
+	return func(c *Config) {
+		c.MaxFeePerGas = f
+	}
+}
+
+func WithMaxResendFailures(n uint64) ConfigOption {
+	return func(c *Config) {
+		c.MaxResendFailures = n
+	}
+}
+
+// DefaultConfig returns the default transaction manager config
+func DefaultConfig() *Config {
+	return &Config{
+		PollInterval:      defaultPollInterval,
+		ResendTimeout:     defaultResendTimeout,
+		BumpPercent:       defaultBumpPercent,
+		MaxResendFailures: defaultMaxResendFailures,
+	}
+}
+
+// Manager sends the transactions of an account and tracks them until
+// they are mined. The transactions that are not mined in time are sent
+// again with higher fees.
+type Manager struct {
+	provider Provider
+	key      web3.Key
+	signer   wallet.Signer
+	config   *Config
+
+	closeCh   chan struct{}
+	closeOnce sync.Once
+}
+
+// NewManager creates a new transaction manager for the account of the key
+func NewManager(provider Provider, key web3.Key, signer wallet.Signer, opts ...ConfigOption) *Manager {
+	config := DefaultConfig()
+	for _, opt := range opts {
+		opt(config)
+	}
+	if config.BumpPercent < minBumpPercent {
+		config.BumpPercent = minBumpPercent
+	}
+	return &Manager{
+		provider: provider,
+		key:      key,
+		signer:   signer,
+		config:   config,
+		closeCh:  make(chan struct{}),
+	}
+}
+
+// Close stops tracking the pending transactions
+func (m *Manager) Close() {
+	m.closeOnce.Do(func() {
+		close(m.closeCh)
+	})
+}
+
+// Send signs the contract transaction with the key of the manager,
+// sends it and tracks it until it is mined
+func (m *Manager) Send(txn *contract.Txn) (*Pending, error) {
+	txn.SetSigner(m.key, m.signer)
+	if err := txn.Do(); err != nil {
+		return nil, err
+	}
+	return m.track(txn.Transaction()), nil
+}
+
+// SendTransaction signs the transaction with the key of the manager,
+// sends it and tracks it until it is mined. The transaction must have
+// the nonce, the fees and the gas limit set.
+func (m *Manager) SendTransaction(txn *web3.Transaction) (*Pending, error) {
+	txn.From = m.key.Address()
+	if err := m.broadcast(txn); err != nil {
+		return nil, err
+	}
+	return m.track(txn), nil
+}
+
+// broadcast signs and sends the transaction
+func (m *Manager) broadcast(txn *web3.Transaction) error {
+	if _, err := m.signer.SignTx(txn, m.key); err != nil {
+		return err
+	}
+	hash, err := m.provider.SendRawTransaction(txn.MarshalRLP())
+	if err != nil {
+		return err
+	}
+	txn.Hash = hash
+	return nil
+}
+
+func (m *Manager) track(txn *web3.Transaction) *Pending {
+	p := &Pending{
+		m:        m,
+		txns:     []*web3.Transaction{txn},
+		last:     txn,
+		lastSent: time.Now(),
+		cancels:  map[web3.Hash]bool{},
+		doneCh:   make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// Result is the final outcome of a tracked transaction
+type Result struct {
+	// Transaction is the transaction that was mined
+	Transaction *web3.Transaction
+
+	// Receipt is the receipt of the mined transaction
+	Receipt *web3.Receipt
+
+	// Index is the position of the mined transaction in the list
+	// of sent transactions, where zero is the original one
+	Index int
+
+	// Cancelled is set if the mined transaction is a cancellation
+	Cancelled bool
+}
+
+// Success returns whether the mined transaction succeeded
+func (r *Result) Success() bool {
+	return r.Receipt.Status == 1
+}
+
+// Pending is a transaction tracked by the manager
+type Pending struct {
+	m *Manager
+
+	lock sync.Mutex
+	// txns are the transactions sent for the nonce
+	txns []*web3.Transaction
+	// last is the last transaction built, which is not in txns if the
+	// node rejected it, and the base for the next fee bump
+	last     *web3.Transaction
+	lastSent time.Time
+
+	// cancelled is set after the transaction is cancelled, the
+	// resends are cancellations too
+	cancelled bool
+	cancels   map[web3.Hash]bool
+
+	// resendFailures is the number of consecutive resends that failed
+	resendFailures uint64
+
+	doneCh chan struct{}
+	result *Result
+	err    error
+}
+
+// Transactions returns the transactions sent for the nonce in order
+func (p *Pending) Transactions() []*web3.Transaction {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return append([]*web3.Transaction{}, p.txns...)
+}
+
+// Done returns a channel that is closed once the transaction is final
+func (p *Pending) Done() <-chan struct{} {
+	return p.doneCh
+}
+
+// Wait blocks until the transaction is final and returns its outcome
+func (p *Pending) Wait() (*Result, error) {
+	<-p.doneCh
+	return p.result, p.err
+}
+
+// Cancel replaces the transaction with a zero value transfer to the
+// sender with the same nonce and higher fees
+func (p *Pending) Cancel() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	select {
+	case <-p.doneCh:
+		return fmt.Errorf("transaction is already final")
+	default:
+	}
+
+	from := p.last.From
+	txn := p.bump(p.last)
+	txn.To = &from
+	txn.Value = big.NewInt(0)
+	txn.Input = nil
+	txn.Gas = transferGas
+	txn.AccessList = nil
+
+	prev := p.last
+	p.cancelled = true
+	if err := p.send(txn); err != nil {
+		p.last = prev
+		p.cancelled = false
+		return err
+	}
+	return nil
+}
+
+func (p *Pending) run() {
+	defer close(p.doneCh)
+
+	for {
+		select {
+		case <-time.After(p.m.config.PollInterval):
+		case <-p.m.closeCh:
+			p.err = ErrClosed
+			return
+		}
+
+		result, err := p.check()
+		if err != nil {
+			p.err = err
+			return
+		}
+		if result != nil {
+			p.result = result
+			return
+		}
+
+		p.lock.Lock()
+		err = nil
+		if time.Since(p.lastSent) >= p.m.config.ResendTimeout {
+			err = p.resend()
+		}
+		p.lock.Unlock()
+
+		if err != nil {
+			p.err = err
+			return
+		}
+	}
+}
+
+// check returns the result if any of the transactions is mined. The errors
+// of the provider are not final and the check is done again on the next poll.
+func (p *Pending) check() (*Result, error) {
+	txns := p.Transactions()
+	first := txns[0]
+
+	// query the nonce first, any transaction mined before it
+	// has a receipt afterwards
+	nonce, err := p.m.provider.GetNonce(first.From, web3.Latest)
+	if err != nil {
+		return nil, nil
+	}
+	complete := true
+	for indx, txn := range txns {
+		receipt, err := p.m.provider.GetTransactionReceipt(txn.Hash)
+		if err != nil {
+			// another transaction may be the one mined
+			complete = false
+			continue
+		}
+		if receipt != nil {
+			p.lock.Lock()
+			cancelled := p.cancels[txn.Hash]
+			p.lock.Unlock()
+
+			return &Result{
+				Transaction: txn,
+				Receipt:     receipt,
+				Index:       indx,
+				Cancelled:   cancelled,
+			}, nil
+		}
+	}
+	if complete && nonce > first.Nonce {
+		return nil, ErrNonceUsed
+	}
+	return nil, nil
+}
+
+// resend sends the last transaction again with higher fees. It only
+// returns an error after MaxResendFailures consecutive failed resends.
+func (p *Pending) resend() error {
+	txn := p.bump(p.last)
+	if maxFee := p.m.config.MaxFeePerGas; maxFee != nil && feeCap(txn).Cmp(maxFee) > 0 {
+		// keep waiting for the last transaction
+		p.lastSent = time.Now()
+		return nil
+	}
+	if err := p.send(txn); err != nil {
+		p.resendFailures++
+		if p.resendFailures >= p.m.config.MaxResendFailures {
+			return fmt.Errorf("failed to resend the transaction %d times: %w", p.resendFailures, err)
+		}
+		return nil
+	}
+	p.resendFailures = 0
+	return nil
+}
+
+// send sends a replacement transaction. It is tracked unless the node
+// rejects it, in which case the next bump starts from its fees.
+func (p *Pending) send(txn *web3.Transaction) error {
+	p.last = txn
+	p.lastSent = time.Now()
+
+	if err := p.m.broadcast(txn); err != nil {
+		if !nonce.IsAlreadyKnown(err) {
+			return err
+		}
+	}
+	p.txns = append(p.txns, txn)
+	if p.cancelled {
+		p.cancels[txn.Hash] = true
+	}
+	return nil
+}
+
+// bump returns an unsigned copy of the transaction with the fees
+// increased by the bump percent of the manager
+func (p *Pending) bump(txn *web3.Transaction) *web3.Transaction {
+	res := *txn
+	res.Hash = web3.Hash{}
+	res.V, res.R, res.S = nil, nil, nil
+
+	percent := p.m.config.BumpPercent
+	if txn.Type == web3.TransactionDynamicFee {
+		res.MaxFeePerGas = bumpFee(txn.MaxFeePerGas, percent)
+		res.MaxPriorityFeePerGas = bumpFee(txn.MaxPriorityFeePerGas, percent)
+	} else {
+		res.GasPrice = bumpFee(new(big.Int).SetUint64(txn.GasPrice), percent).Uint64()
+	}
+	return &res
+}
+
+// bumpFee increases the fee by the percent, rounded up and
+// at least by one so that a zero fee is increased too
+func bumpFee(fee *big.Int, percent uint64) *big.Int {
+	if fee == nil {
+		fee = new(big.Int)
+	}
+	inc := new(big.Int).Mul(fee, new(big.Int).SetUint64(percent))
+	inc.Add(inc, big.NewInt(99))
+	inc.Div(inc, big.NewInt(100))
+	if inc.Sign() == 0 {
+		inc.SetUint64(1)
+	}
+	return inc.Add(inc, fee)
+}
+
+func feeCap(txn *web3.Transaction) *big.Int {
+	if txn.Type == web3.TransactionDynamicFee {
+		return txn.MaxFeePerGas
+	}
+	return new(big.Int).SetUint64(txn.GasPrice)
+}
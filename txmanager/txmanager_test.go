@@ -0,0 +1,366 @@
+package txmanager
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	web3 "github.com/mover-code/golang-web3"
+	"github.com/mover-code/golang-web3/abi"
+	"github.com/mover-code/golang-web3/contract"
+	"github.com/mover-code/golang-web3/jsonrpc"
+	"github.com/mover-code/golang-web3/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+const testChainID = 1337
+
+// mockProvider is a node with a pool that only mines the
+// transactions accepted by the mine function
+type mockProvider struct {
+	lock     sync.Mutex
+	pool     map[uint64]*web3.Transaction
+	receipts map[web3.Hash]*web3.Receipt
+	nonce    uint64
+	mine     func(txn *web3.Transaction) bool
+
+	// sendErr and receiptErrs are the errors of the
+	// node to send transactions and to query receipts
+	sendErr     error
+	receiptErrs map[web3.Hash]error
+}
+
+func newMockProvider(mine func(txn *web3.Transaction) bool) *mockProvider {
+	return &mockProvider{
+		pool:        map[uint64]*web3.Transaction{},
+		receipts:    map[web3.Hash]*web3.Receipt{},
+		mine:        mine,
+		receiptErrs: map[web3.Hash]error{},
+	}
+}
+
+func (m *mockProvider) SendRawTransaction(data []byte) (web3.Hash, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.sendErr != nil {
+		return web3.Hash{}, m.sendErr
+	}
+	txn, err := wallet.DecodeTransaction(wallet.NewEIP155Signer(testChainID), data)
+	if err != nil {
+		return web3.Hash{}, err
+	}
+	if txn.Nonce < m.nonce {
+		return web3.Hash{}, fmt.Errorf("nonce too low")
+	}
+	if prev, ok := m.pool[txn.Nonce]; ok {
+		// replacement rules
+		if !bumped(feeCap(prev), feeCap(txn)) {
+			return web3.Hash{}, fmt.Errorf("replacement transaction underpriced")
+		}
+		if txn.Type == web3.TransactionDynamicFee && !bumped(prev.MaxPriorityFeePerGas, txn.MaxPriorityFeePerGas) {
+			return web3.Hash{}, fmt.Errorf("replacement transaction underpriced")
+		}
+	}
+	txn.Hash = web3.BytesToHash(wallet.Keccake256(data))
+	m.pool[txn.Nonce] = txn
+
+	if m.mine(txn) {
+		m.receipts[txn.Hash] = &web3.Receipt{TransactionHash: txn.Hash, Status: 1}
+		m.nonce = txn.Nonce + 1
+	}
+	return txn.Hash, nil
+}
+
+func bumped(prev, next *big.Int) bool {
+	min := new(big.Int).Mul(prev, big.NewInt(110))
+	return new(big.Int).Mul(next, big.NewInt(100)).Cmp(min) >= 0
+}
+
+func (m *mockProvider) GetTransactionReceipt(hash web3.Hash) (*web3.Receipt, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err, ok := m.receiptErrs[hash]; ok {
+		return nil, err
+	}
+	return m.receipts[hash], nil
+}
+
+func (m *mockProvider) GetNonce(addr web3.Address, blockNumber web3.BlockNumberOrHash) (uint64, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.nonce, nil
+}
+
+func newTestManager(t *testing.T, provider Provider, opts ...ConfigOption) (*Manager, *wallet.Key) {
+	key, err := wallet.GenerateKey()
+	assert.NoError(t, err)
+
+	opts = append([]ConfigOption{
+		WithPollInterval(10 * time.Millisecond),
+		WithResendTimeout(20 * time.Millisecond),
+	}, opts...)
+	m := NewManager(provider, key, wallet.NewEIP155Signer(testChainID), opts...)
+	t.Cleanup(m.Close)
+	return m, key
+}
+
+func TestManager_SpeedUpLegacy(t *testing.T) {
+	// mine the transaction after the second resend
+	provider := newMockProvider(func(txn *web3.Transaction) bool {
+		return txn.GasPrice >= 121
+	})
+	m, key := newTestManager(t, provider)
+
+	to := web3.Address{0x1}
+	pending, err := m.SendTransaction(&web3.Transaction{
+		To:       &to,
+		Value:    big.NewInt(1),
+		GasPrice: 100,
+		Gas:      transferGas,
+	})
+	assert.NoError(t, err)
+
+	result, err := pending.Wait()
+	assert.NoError(t, err)
+	assert.True(t, result.Success())
+	assert.False(t, result.Cancelled)
+	assert.Equal(t, result.Index, 2)
+	assert.Equal(t, result.Transaction.GasPrice, uint64(121))
+	assert.Equal(t, result.Transaction.From, key.Address())
+
+	txns := pending.Transactions()
+	assert.Len(t, txns, 3)
+	assert.Equal(t, txns[1].GasPrice, uint64(110))
+	assert.Equal(t, result.Receipt.TransactionHash, txns[2].Hash)
+}
+
+func TestManager_SpeedUpDynamicFee(t *testing.T) {
+	provider := newMockProvider(func(txn *web3.Transaction) bool {
+		return txn.MaxPriorityFeePerGas.Uint64() > 1
+	})
+	m, _ := newTestManager(t, provider)
+
+	to := web3.Address{0x1}
+	pending, err := m.SendTransaction(&web3.Transaction{
+		Type:                 web3.TransactionDynamicFee,
+		To:                   &to,
+		MaxFeePerGas:         big.NewInt(100),
+		MaxPriorityFeePerGas: big.NewInt(1),
+		Gas:                  transferGas,
+	})
+	assert.NoError(t, err)
+
+	result, err := pending.Wait()
+	assert.NoError(t, err)
+	assert.Equal(t, result.Index, 1)
+
+	// the bump of the tip is rounded up
+	assert.Equal(t, result.Transaction.MaxPriorityFeePerGas, big.NewInt(2))
+	assert.Equal(t, result.Transaction.MaxFeePerGas, big.NewInt(110))
+}
+
+func TestManager_Cancel(t *testing.T) {
+	provider := newMockProvider(func(txn *web3.Transaction) bool {
+		return txn.Value.Sign() == 0
+	})
+	m, key := newTestManager(t, provider, WithResendTimeout(time.Hour))
+
+	to := web3.Address{0x1}
+	pending, err := m.SendTransaction(&web3.Transaction{
+		To:       &to,
+		Value:    big.NewInt(1),
+		Input:    []byte{0x1},
+		GasPrice: 100,
+		Gas:      100000,
+		Nonce:    0,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, pending.Cancel())
+
+	result, err := pending.Wait()
+	assert.NoError(t, err)
+	assert.True(t, result.Cancelled)
+	assert.Equal(t, result.Index, 1)
+	assert.Equal(t, *result.Transaction.To, key.Address())
+	assert.Equal(t, result.Transaction.Gas, uint64(transferGas))
+	assert.Equal(t, result.Transaction.GasPrice, uint64(110))
+	assert.Empty(t, result.Transaction.Input)
+
+	assert.Error(t, pending.Cancel())
+}
+
+func TestManager_MaxFeePerGas(t *testing.T) {
+	provider := newMockProvider(func(txn *web3.Transaction) bool {
+		return false
+	})
+	m, _ := newTestManager(t, provider, WithMaxFeePerGas(big.NewInt(120)))
+
+	to := web3.Address{0x1}
+	pending, err := m.SendTransaction(&web3.Transaction{
+		To:       &to,
+		GasPrice: 100,
+		Gas:      transferGas,
+	})
+	assert.NoError(t, err)
+
+	time.Sleep(200 * time.Millisecond)
+	assert.Len(t, pending.Transactions(), 2)
+
+	// the nonce is used by a transaction that is not tracked
+	provider.lock.Lock()
+	provider.nonce = 1
+	provider.lock.Unlock()
+
+	_, err = pending.Wait()
+	assert.Equal(t, err, ErrNonceUsed)
+}
+
+func TestManager_ReceiptError(t *testing.T) {
+	provider := newMockProvider(func(txn *web3.Transaction) bool {
+		return txn.GasPrice >= 110
+	})
+	m, _ := newTestManager(t, provider)
+
+	to := web3.Address{0x1}
+	pending, err := m.SendTransaction(&web3.Transaction{
+		To:       &to,
+		GasPrice: 100,
+		Gas:      transferGas,
+	})
+	assert.NoError(t, err)
+
+	// the receipt of the first transaction cannot be queried
+	provider.lock.Lock()
+	provider.receiptErrs[pending.Transactions()[0].Hash] = fmt.Errorf("internal error")
+	provider.lock.Unlock()
+
+	result, err := pending.Wait()
+	assert.NoError(t, err)
+	assert.Equal(t, result.Index, 1)
+}
+
+func TestManager_ResendFailures(t *testing.T) {
+	provider := newMockProvider(func(txn *web3.Transaction) bool {
+		return false
+	})
+	m, _ := newTestManager(t, provider, WithMaxResendFailures(2))
+
+	to := web3.Address{0x1}
+	pending, err := m.SendTransaction(&web3.Transaction{
+		To:       &to,
+		GasPrice: 100,
+		Gas:      transferGas,
+	})
+	assert.NoError(t, err)
+
+	provider.lock.Lock()
+	provider.sendErr = fmt.Errorf("connection refused")
+	provider.lock.Unlock()
+
+	_, err = pending.Wait()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "connection refused")
+	assert.Len(t, pending.Transactions(), 1)
+}
+
+// newMockNode serves the mock provider over JSON-RPC
+func newMockNode(t *testing.T, provider *mockProvider) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string
+			Params []interface{}
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var result interface{}
+		var err error
+		switch req.Method {
+		case "eth_getTransactionCount":
+			var nonce uint64
+			nonce, err = provider.GetNonce(web3.Address{}, web3.Pending)
+			result = fmt.Sprintf("0x%x", nonce)
+		case "eth_sendRawTransaction":
+			var raw []byte
+			raw, err = hex.DecodeString(strings.TrimPrefix(req.Params[0].(string), "0x"))
+			assert.NoError(t, err)
+			var hash web3.Hash
+			hash, err = provider.SendRawTransaction(raw)
+			result = hash.String()
+		case "eth_getTransactionReceipt":
+			var receipt *web3.Receipt
+			receipt, err = provider.GetTransactionReceipt(web3.HexToHash(req.Params[0].(string)))
+			if receipt != nil {
+				result = json.RawMessage(fmt.Sprintf(`{
+					"status": "0x1",
+					"from": "%s",
+					"transactionHash": "%s",
+					"blockHash": "%s",
+					"transactionIndex": "0x0",
+					"blockNumber": "0x1",
+					"gasUsed": "0x5208",
+					"cumulativeGasUsed": "0x5208",
+					"logsBloom": "0x%s",
+					"logs": []
+				}`, web3.Address{}, receipt.TransactionHash, web3.Hash{0x1}, strings.Repeat("00", 256)))
+			}
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+			err = fmt.Errorf("method not found")
+		}
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": 1}
+		if err != nil {
+			resp["error"] = map[string]interface{}{"code": -32000, "message": err.Error()}
+		} else {
+			resp["result"] = result
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+}
+
+func TestManager_Send(t *testing.T) {
+	provider := newMockProvider(func(txn *web3.Transaction) bool {
+		return txn.GasPrice >= 110
+	})
+	srv := newMockNode(t, provider)
+	defer srv.Close()
+
+	client, err := jsonrpc.NewClient(srv.URL)
+	assert.NoError(t, err)
+
+	m, key := newTestManager(t, client.Eth())
+
+	abi0, err := abi.NewABI(`[
+		{"type": "function", "name": "set", "inputs": [{"name": "val", "type": "uint256"}], "outputs": []}
+	]`)
+	assert.NoError(t, err)
+
+	to := web3.Address{0x1}
+	c := contract.NewContract(to, abi0, client)
+
+	// the transaction is signed with the key of the manager
+	pending, err := m.Send(c.Txn("set", big.NewInt(1)).SetGasPrice(100).SetGasLimit(100000))
+	assert.NoError(t, err)
+
+	result, err := pending.Wait()
+	assert.NoError(t, err)
+	assert.True(t, result.Success())
+	assert.Equal(t, result.Index, 1)
+	assert.Equal(t, result.Transaction.From, key.Address())
+	assert.Equal(t, *result.Transaction.To, to)
+	assert.Equal(t, result.Transaction.GasPrice, uint64(110))
+	assert.Equal(t, result.Transaction.Gas, uint64(100000))
+
+	method := abi0.Methods["set"]
+	assert.Equal(t, result.Transaction.Input[:4], method.ID())
+}
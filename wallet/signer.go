@@ -1,6 +1,7 @@
 package wallet
 
 import (
+	"fmt"
 	"math/big"
 
 	web3 "github.com/mover-code/golang-web3"
@@ -13,9 +14,11 @@ type Signer interface {
 	RecoverSender(tx *web3.Transaction) (web3.Address, error)
 
 	// SignTx signs a transaction
-	SignTx(tx *web3.Transaction, key *Key) (*web3.Transaction, error)
+	SignTx(tx *web3.Transaction, key web3.Key) (*web3.Transaction, error)
 }
 
+// EIP1155Signer signs legacy transactions with EIP-155 replay protection
+// and typed (EIP-2718) transactions with the chain id in the payload.
 type EIP1155Signer struct {
 	chainID uint64
 }
@@ -24,24 +27,57 @@ func NewEIP155Signer(chainID uint64) *EIP1155Signer {
 	return &EIP1155Signer{chainID: chainID}
 }
 
+// RecoverSender returns the sender of the transaction. It fails if the
+// transaction is signed for another chain or the signature is not valid.
 func (e *EIP1155Signer) RecoverSender(tx *web3.Transaction) (web3.Address, error) {
-	v := new(big.Int).SetBytes(tx.V).Uint64()
-	v -= e.chainID * 2
-	v -= 8
-	v -= 27
+	chainID := e.chainID
+	v := new(big.Int).SetBytes(tx.V)
+
+	if tx.Type == web3.TransactionLegacy {
+		if v.Cmp(big.NewInt(27)) == 0 || v.Cmp(big.NewInt(28)) == 0 {
+			// pre EIP155 transaction without replay protection
+			chainID = 0
+			v.Sub(v, big.NewInt(27))
+		} else {
+			// EIP155 values are chain id * 2 + 35 + y-parity
+			if v.Cmp(big.NewInt(35)) < 0 {
+				return web3.Address{}, fmt.Errorf("invalid signature v value %s", v)
+			}
+			v.Sub(v, big.NewInt(35))
+			txChainID := new(big.Int).Rsh(v, 1)
+			if !txChainID.IsUint64() || txChainID.Uint64() != e.chainID {
+				return web3.Address{}, fmt.Errorf("transaction chain id %s does not match the signer chain id %d", txChainID, e.chainID)
+			}
+			v.And(v, big.NewInt(1))
+		}
+	} else if tx.ChainID != nil {
+		if !tx.ChainID.IsUint64() || tx.ChainID.Uint64() != e.chainID {
+			return web3.Address{}, fmt.Errorf("transaction chain id %s does not match the signer chain id %d", tx.ChainID, e.chainID)
+		}
+	}
+	if !v.IsUint64() || v.Uint64() > 1 {
+		return web3.Address{}, fmt.Errorf("invalid signature v value %s", v)
+	}
 
-	sig, err := encodeSignature(tx.R, tx.S, byte(v))
+	sig, err := encodeSignature(tx.R, tx.S, byte(v.Uint64()))
 	if err != nil {
 		return web3.Address{}, err
 	}
-	addr, err := Ecrecover(signHash(tx, e.chainID), sig)
+	addr, err := Ecrecover(signHash(tx, chainID), sig)
 	if err != nil {
 		return web3.Address{}, err
 	}
 	return addr, nil
 }
 
-func (e *EIP1155Signer) SignTx(tx *web3.Transaction, key *Key) (*web3.Transaction, error) {
+func (e *EIP1155Signer) SignTx(tx *web3.Transaction, key web3.Key) (*web3.Transaction, error) {
+	if tx.ChainID != nil {
+		if !tx.ChainID.IsUint64() || tx.ChainID.Uint64() != e.chainID {
+			return nil, fmt.Errorf("transaction chain id %s does not match the signer chain id %d", tx.ChainID, e.chainID)
+		}
+	} else if tx.Type != web3.TransactionLegacy {
+		tx.ChainID = new(big.Int).SetUint64(e.chainID)
+	}
 	hash := signHash(tx, e.chainID)
 
 	sig, err := key.Sign(hash)
@@ -49,20 +85,46 @@ func (e *EIP1155Signer) SignTx(tx *web3.Transaction, key *Key) (*web3.Transactio
 		return nil, err
 	}
 
-	vv := uint64(sig[64]) + 35 + e.chainID*2
+	// typed transactions only carry the y-parity of the signature
+	vv := uint64(sig[64])
+	if tx.Type == web3.TransactionLegacy {
+		vv = vv + 35 + e.chainID*2
+	}
 
-	tx.R = sig[:32]
-	tx.S = sig[32:64]
+	tx.R = web3.TrimLeftZeros(sig[:32])
+	tx.S = web3.TrimLeftZeros(sig[32:64])
 	tx.V = new(big.Int).SetUint64(vv).Bytes()
 	return tx, nil
 }
 
+// DecodeTransaction decodes a raw signed transaction and recovers its sender
+func DecodeTransaction(signer Signer, raw []byte) (*web3.Transaction, error) {
+	tx := new(web3.Transaction)
+	if err := tx.UnmarshalRLP(raw); err != nil {
+		return nil, err
+	}
+	from, err := signer.RecoverSender(tx)
+	if err != nil {
+		return nil, err
+	}
+	tx.From = from
+	return tx, nil
+}
+
 func signHash(tx *web3.Transaction, chainID uint64) []byte {
 	a := fastrlp.DefaultArenaPool.Get()
 
 	v := a.NewArray()
+	if tx.Type != web3.TransactionLegacy {
+		v.Set(a.NewUint(chainID))
+	}
 	v.Set(a.NewUint(tx.Nonce))
-	v.Set(a.NewUint(tx.GasPrice))
+	if tx.Type == web3.TransactionDynamicFee || tx.Type == web3.TransactionBlob {
+		v.Set(a.NewBigInt(tx.MaxPriorityFeePerGas))
+		v.Set(a.NewBigInt(tx.MaxFeePerGas))
+	} else {
+		v.Set(a.NewUint(tx.GasPrice))
+	}
 	v.Set(a.NewUint(tx.Gas))
 	if tx.To == nil {
 		v.Set(a.NewNull())
@@ -72,19 +134,38 @@ func signHash(tx *web3.Transaction, chainID uint64) []byte {
 	v.Set(a.NewBigInt(tx.Value))
 	v.Set(a.NewCopyBytes(tx.Input))
 
+	if tx.Type != web3.TransactionLegacy {
+		v.Set(tx.AccessList.MarshalRLPWith(a))
+	}
+	if tx.Type == web3.TransactionBlob {
+		v.Set(a.NewBigInt(tx.MaxFeePerBlobGas))
+		hashes := a.NewArray()
+		for _, h := range tx.BlobVersionedHashes {
+			hashes.Set(a.NewCopyBytes(h[:]))
+		}
+		v.Set(hashes)
+	}
+
 	// EIP155
-	if chainID != 0 {
+	if chainID != 0 && tx.Type == web3.TransactionLegacy {
 		v.Set(a.NewUint(chainID))
 		v.Set(a.NewUint(0))
 		v.Set(a.NewUint(0))
 	}
 
-	hash := keccak256(v.MarshalTo(nil))
+	var dst []byte
+	if tx.Type != web3.TransactionLegacy {
+		dst = append(dst, byte(tx.Type))
+	}
+	hash := keccak256(v.MarshalTo(dst))
 	fastrlp.DefaultArenaPool.Put(a)
 	return hash
 }
 
 func encodeSignature(R, S []byte, V byte) ([]byte, error) {
+	if len(R) > 32 || len(S) > 32 {
+		return nil, fmt.Errorf("invalid signature values length")
+	}
 	sig := make([]byte, 65)
 	copy(sig[32-len(R):32], R)
 	copy(sig[64-len(S):64], S)
@@ -5,6 +5,9 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -35,3 +38,240 @@ func TestSigner_EIP1155(t *testing.T) {
 		assert.NotEqual(t, from, from2)
 	*/
 }
+
+func TestSigner_EIP1559(t *testing.T) {
+	signer := NewEIP155Signer(1337)
+
+	addr0 := web3.Address{0x1}
+	key, err := GenerateKey()
+	assert.NoError(t, err)
+
+	txn := &web3.Transaction{
+		Type:                 web3.TransactionDynamicFee,
+		To:                   &addr0,
+		Value:                big.NewInt(10),
+		Nonce:                5,
+		Gas:                  21000,
+		MaxPriorityFeePerGas: big.NewInt(2000000000),
+		MaxFeePerGas:         big.NewInt(30000000000),
+	}
+	txn, err = signer.SignTx(txn, key)
+	assert.NoError(t, err)
+	assert.Equal(t, txn.ChainID, big.NewInt(1337))
+
+	from, err := signer.RecoverSender(txn)
+	assert.NoError(t, err)
+	assert.Equal(t, from, key.addr)
+
+	// the typed envelope must be accepted by go-ethereum
+	raw := txn.MarshalRLP()
+	assert.Equal(t, raw[0], byte(web3.TransactionDynamicFee))
+
+	gethTxn := new(types.Transaction)
+	assert.NoError(t, gethTxn.UnmarshalBinary(raw))
+	assert.Equal(t, gethTxn.GasTipCap(), txn.MaxPriorityFeePerGas)
+	assert.Equal(t, gethTxn.GasFeeCap(), txn.MaxFeePerGas)
+
+	gethFrom, err := types.Sender(types.NewLondonSigner(big.NewInt(1337)), gethTxn)
+	assert.NoError(t, err)
+	assert.Equal(t, gethFrom.Bytes(), key.addr[:])
+}
+
+func TestSigner_EIP4844(t *testing.T) {
+	signer := NewEIP155Signer(1337)
+
+	addr0 := web3.Address{0x1}
+	key, err := GenerateKey()
+	assert.NoError(t, err)
+
+	txn := &web3.Transaction{
+		Type:                 web3.TransactionBlob,
+		To:                   &addr0,
+		Value:                big.NewInt(0),
+		Nonce:                5,
+		Gas:                  21000,
+		MaxPriorityFeePerGas: big.NewInt(2000000000),
+		MaxFeePerGas:         big.NewInt(30000000000),
+		MaxFeePerBlobGas:     big.NewInt(1000000000),
+		BlobVersionedHashes:  []web3.Hash{{0x1, 0x1}},
+	}
+	txn, err = signer.SignTx(txn, key)
+	assert.NoError(t, err)
+
+	// the blob fields are part of the signed payload
+	raw := txn.MarshalRLP()
+	assert.Equal(t, raw[0], byte(web3.TransactionBlob))
+
+	found, err := DecodeTransaction(signer, raw)
+	assert.NoError(t, err)
+	assert.Equal(t, found.From, key.addr)
+	assert.Equal(t, found.MaxFeePerBlobGas, txn.MaxFeePerBlobGas)
+	assert.Equal(t, found.BlobVersionedHashes, txn.BlobVersionedHashes)
+
+	found.BlobVersionedHashes[0] = web3.Hash{0x1, 0x2}
+	from, err := signer.RecoverSender(found)
+	assert.NoError(t, err)
+	assert.NotEqual(t, from, key.addr)
+}
+
+func TestSigner_EIP2930(t *testing.T) {
+	signer := NewEIP155Signer(1337)
+
+	addr0 := web3.Address{0x1}
+	key, err := GenerateKey()
+	assert.NoError(t, err)
+
+	txn := &web3.Transaction{
+		Type:     web3.TransactionAccessList,
+		To:       &addr0,
+		Value:    big.NewInt(10),
+		Nonce:    1,
+		Gas:      30000,
+		GasPrice: 1000000000,
+		AccessList: web3.AccessList{
+			{
+				Address: web3.Address{0x2},
+				Storage: []web3.Hash{{0x1}, {0x2}},
+			},
+			{
+				Address: web3.Address{0x3},
+			},
+		},
+	}
+	txn, err = signer.SignTx(txn, key)
+	assert.NoError(t, err)
+
+	from, err := signer.RecoverSender(txn)
+	assert.NoError(t, err)
+	assert.Equal(t, from, key.addr)
+
+	raw := txn.MarshalRLP()
+	assert.Equal(t, raw[0], byte(web3.TransactionAccessList))
+
+	gethTxn := new(types.Transaction)
+	assert.NoError(t, gethTxn.UnmarshalBinary(raw))
+	assert.Len(t, gethTxn.AccessList(), 2)
+	assert.Len(t, gethTxn.AccessList()[0].StorageKeys, 2)
+
+	gethFrom, err := types.Sender(types.NewEIP2930Signer(big.NewInt(1337)), gethTxn)
+	assert.NoError(t, err)
+	assert.Equal(t, gethFrom.Bytes(), key.addr[:])
+}
+
+func TestSigner_DecodeTransaction(t *testing.T) {
+	chainID := big.NewInt(1337)
+	signer := NewEIP155Signer(chainID.Uint64())
+
+	gethKey, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	addr := crypto.PubkeyToAddress(gethKey.PublicKey)
+	to := common.Address{0x1}
+
+	cases := []types.TxData{
+		&types.LegacyTx{
+			Nonce:    1,
+			GasPrice: big.NewInt(10),
+			Gas:      21000,
+			To:       &to,
+			Value:    big.NewInt(1),
+		},
+		&types.AccessListTx{
+			ChainID:  chainID,
+			Nonce:    2,
+			GasPrice: big.NewInt(10),
+			Gas:      30000,
+			Value:    big.NewInt(0),
+			Data:     []byte{0x1, 0x2},
+			AccessList: types.AccessList{
+				{Address: to, StorageKeys: []common.Hash{{0x1}}},
+			},
+		},
+		&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     3,
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(100),
+			Gas:       21000,
+			To:        &to,
+			Value:     big.NewInt(5),
+		},
+	}
+
+	for _, c := range cases {
+		gethTxn, err := types.SignNewTx(gethKey, types.NewLondonSigner(chainID), c)
+		assert.NoError(t, err)
+
+		raw, err := gethTxn.MarshalBinary()
+		assert.NoError(t, err)
+
+		txn, err := DecodeTransaction(signer, raw)
+		assert.NoError(t, err)
+
+		assert.Equal(t, txn.Type, web3.TransactionType(gethTxn.Type()))
+		assert.Equal(t, txn.Hash[:], gethTxn.Hash().Bytes())
+		assert.Equal(t, txn.From[:], addr.Bytes())
+		assert.Equal(t, txn.Nonce, gethTxn.Nonce())
+
+		// encoding it back must produce the same bytes
+		assert.Equal(t, txn.MarshalRLP(), raw)
+	}
+}
+
+func TestSigner_ChainID(t *testing.T) {
+	signer := NewEIP155Signer(1337)
+
+	addr0 := web3.Address{0x1}
+	key, err := GenerateKey()
+	assert.NoError(t, err)
+
+	// the transaction is for another chain
+	txn := &web3.Transaction{
+		Type:                 web3.TransactionDynamicFee,
+		ChainID:              big.NewInt(1),
+		To:                   &addr0,
+		Value:                big.NewInt(10),
+		MaxPriorityFeePerGas: big.NewInt(1),
+		MaxFeePerGas:         big.NewInt(10),
+	}
+	_, err = signer.SignTx(txn, key)
+	assert.Error(t, err)
+
+	txn.ChainID = big.NewInt(1337)
+	txn, err = signer.SignTx(txn, key)
+	assert.NoError(t, err)
+
+	from, err := signer.RecoverSender(txn)
+	assert.NoError(t, err)
+	assert.Equal(t, from, key.addr)
+
+	// the signers of other chains reject the typed transaction
+	_, err = NewEIP155Signer(1).RecoverSender(txn)
+	assert.Error(t, err)
+	_, err = DecodeTransaction(NewEIP155Signer(1), txn.MarshalRLP())
+	assert.Error(t, err)
+
+	// and the legacy transaction
+	legacy := &web3.Transaction{
+		To:       &addr0,
+		Value:    big.NewInt(10),
+		GasPrice: 1,
+		Gas:      21000,
+	}
+	legacy, err = signer.SignTx(legacy, key)
+	assert.NoError(t, err)
+
+	_, err = NewEIP155Signer(1).RecoverSender(legacy)
+	assert.Error(t, err)
+	_, err = DecodeTransaction(NewEIP155Signer(1), legacy.MarshalRLP())
+	assert.Error(t, err)
+
+	// the y-parity of a typed transaction is either 0 or 1
+	txn.V = []byte{0x2}
+	_, err = signer.RecoverSender(txn)
+	assert.Error(t, err)
+
+	// v values that do not fit in a byte are not truncated
+	legacy.V = new(big.Int).Add(new(big.Int).SetBytes(legacy.V), big.NewInt(256)).Bytes()
+	_, err = signer.RecoverSender(legacy)
+	assert.Error(t, err)
+}